@@ -57,7 +57,7 @@ var backupDelete = &cobra.Command{
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		client := common.DefaultClient()
-
+		deleted := []string{}
 		for _, idOrName := range args {
 			backup, err := client.CinderV2().FindBackup(idOrName)
 			if err != nil {
@@ -67,10 +67,17 @@ var backupDelete = &cobra.Command{
 			err = client.CinderV2().DeleteBackup(backup.Id)
 			if err == nil {
 				fmt.Printf("Requested to delete backup %s\n", idOrName)
+				deleted = append(deleted, backup.Id)
 			} else {
 				println(err)
 			}
 		}
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
+			for _, id := range deleted {
+				err := client.CinderV2().WaitBackupDeleted(id, timeout)
+				utility.LogIfError(err, false, "wait backup %s deleted failed", id)
+			}
+		}
 	},
 }
 
@@ -91,6 +98,10 @@ var backupCreate = &cobra.Command{
 		utility.LogIfError(err, true, "create backup failed")
 		backup, err = client.CinderV2().GetBackup(backup.Id)
 		utility.LogIfError(err, true, "show backup failed")
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
+			backup, err = client.CinderV2().WaitBackupStatus(backup.Id, "available", timeout)
+			utility.LogIfError(err, true, "wait backup available failed")
+		}
 		common.PrintBackup(*backup)
 	},
 }
@@ -103,6 +114,7 @@ func init() {
 
 	backupCreate.Flags().Bool("force", false, "Ignores the current status of the volume ")
 	backupCreate.Flags().StringP("name", "n", "", "backup name")
+	common.RegistryWaitFlags(backupCreate, backupDelete)
 
 	Backup.AddCommand(
 		backupList, backupShow, backupCreate,
@@ -57,7 +57,7 @@ var snapshotDelete = &cobra.Command{
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		client := common.DefaultClient()
-
+		deleted := []string{}
 		for _, idOrName := range args {
 			snapshot, err := client.CinderV2().FindSnapshot(idOrName)
 			if err != nil {
@@ -67,10 +67,17 @@ var snapshotDelete = &cobra.Command{
 			err = client.CinderV2().DeleteSnapshot(snapshot.Id)
 			if err == nil {
 				fmt.Printf("Requested to delete snapshot %s\n", idOrName)
+				deleted = append(deleted, snapshot.Id)
 			} else {
 				println(err)
 			}
 		}
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
+			for _, id := range deleted {
+				err := client.CinderV2().WaitSnapshotDeleted(id, timeout)
+				utility.LogIfError(err, false, "wait snapshot %s deleted failed", id)
+			}
+		}
 	},
 }
 
@@ -91,6 +98,10 @@ var snapshotCreate = &cobra.Command{
 		utility.LogIfError(err, true, "create snaphost failed")
 		snapshot, err = client.CinderV2().GetSnapshot(snapshot.Id)
 		utility.LogIfError(err, true, "show snapshot failed")
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
+			snapshot, err = client.CinderV2().WaitSnapshotStatus(snapshot.Id, "available", timeout)
+			utility.LogIfError(err, true, "wait snapshot available failed")
+		}
 		common.PrintSnapshot(*snapshot)
 	},
 }
@@ -120,6 +131,7 @@ func init() {
 
 	snapshotCreate.Flags().Bool("force", false, "Ignores the current status of the volume ")
 	snapshotCreate.Flags().StringP("name", "n", "", "snapshot name")
+	common.RegistryWaitFlags(snapshotCreate, snapshotDelete)
 
 	Snapshot.AddCommand(
 		snapshotList, snapshotShow, snapshotCreate, snapshotRevert,
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
 	"github.com/BytemanD/skyman/openstack/model/cinder"
 	"github.com/BytemanD/skyman/utility"
@@ -70,6 +71,7 @@ var volumeDelete = &cobra.Command{
 		force, _ := cmd.Flags().GetBool("force")
 		cascade, _ := cmd.Flags().GetBool("cascade")
 
+		deleted := []string{}
 		for _, idOrName := range args {
 			volume, err := client.CinderV2().FindVolume(idOrName)
 			if err != nil {
@@ -79,10 +81,17 @@ var volumeDelete = &cobra.Command{
 			err = client.CinderV2().DeleteVolume(volume.Id, force, cascade)
 			if err == nil {
 				println("Requested to delete volume", idOrName)
+				deleted = append(deleted, volume.Id)
 			} else {
 				println(err)
 			}
 		}
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
+			for _, id := range deleted {
+				err := client.CinderV2().WaitVolumeDeleted(id, timeout)
+				utility.LogIfError(err, false, "wait volume %s deleted failed", id)
+			}
+		}
 	},
 }
 
@@ -113,6 +122,10 @@ var volumeCreate = &cobra.Command{
 			println(err)
 			os.Exit(1)
 		}
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
+			volume, err = client.CinderV2().WaitVolumeStatus(volume.Id, "available", timeout)
+			utility.LogError(err, "wait volume available failed", true)
+		}
 		common.PrintVolume(*volume)
 	},
 }
@@ -137,6 +150,15 @@ var volumeExtend = &cobra.Command{
 
 		err = client.CinderV2().ExtendVolume(volume.Id, size)
 		utility.LogError(err, "extend volume falied", true)
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
+			_, err = client.CinderV2().VolumeWaiter(volume.Id, volume.Status).
+				Until(func(v *cinder.Volume) bool {
+					return v.Status == volume.Status && int(v.Size) == size
+				}).
+				WithTimeout(timeout).Wait()
+			utility.LogError(err, "wait volume extended failed", true)
+			console.Info("volume %s extended to %dG", volume.Id, size)
+		}
 	},
 }
 var volumeRetype = &cobra.Command{
@@ -160,9 +182,22 @@ var volumeRetype = &cobra.Command{
 		client := common.DefaultClient()
 		volume, err := client.CinderV2().FindVolume(idOrName)
 		utility.LogError(err, "get volume falied", true)
+		volumeType, err := client.CinderV2().FindType(newType)
+		utility.LogError(err, "get volume type falied", true)
 
 		err = client.CinderV2().RetypeVolume(volume.Id, newType, migrationPolicy)
 		utility.LogError(err, "extend volume falied", true)
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
+			_, err = client.CinderV2().VolumeWaiter(volume.Id, volume.Status).
+				Until(func(v *cinder.Volume) bool {
+					// cinder 返回的是类型名称
+					return v.Status == volume.Status &&
+						(v.VolumeType == volumeType.Name || v.VolumeType == volumeType.Id)
+				}).
+				WithTimeout(timeout).Wait()
+			utility.LogError(err, "wait volume retyped failed", true)
+			console.Info("volume %s retyped to %s", volume.Id, volumeType.Name)
+		}
 	},
 }
 
@@ -186,6 +221,8 @@ func init() {
 		fmt.Sprintf("Migration policy during retype of volume,\ninvalid values: %s",
 			cinder.MIGRATION_POLICYS))
 
+	common.RegistryWaitFlags(volumeCreate, volumeDelete, volumeExtend, volumeRetype)
+
 	Volume.AddCommand(
 		volumeList, volumeShow, volumeCreate, volumeExtend, volumeRetype,
 		volumeDelete,
@@ -1,5 +1,7 @@
 package flags
 
+import "time"
+
 type ServerListFlags struct {
 	Name          *string
 	Host          *string
@@ -32,7 +34,6 @@ type ServerCreateFlags struct {
 	UserData   *string
 	KeyName    *string
 	AdminPass  *string
-	Wait       *bool
 }
 type ServerSetFlags struct {
 	Name           *string
@@ -43,26 +44,19 @@ type ServerSetFlags struct {
 	Description    *string
 }
 
-type ServerDeleteFlags struct {
-	Wait *bool
-}
-
 type ServerRebootFlags struct {
 	Hard *bool
-	Wait *bool
 }
 
 type ServerResizeFlags struct {
 	Confirm *bool
 	Revert  *bool
 	Flavor  *string
-	Wait    *bool
 }
 type ServerMigrateFlags struct {
 	Live         *bool
 	BlockMigrate *bool
 	Host         *string
-	Wait         *bool
 }
 type ServerRebuildFlags struct {
 	Image         *string
@@ -114,7 +108,11 @@ type ComputeServiceDisableFlags struct {
 }
 
 type ConsoleLogFlags struct {
-	Lines *uint
+	Lines    *uint
+	Follow   *bool
+	WaitFor  *string
+	Timeout  *time.Duration
+	Interval *time.Duration
 }
 
 type ServerActionFlags struct {
@@ -148,8 +146,9 @@ type FlavorCreateFlags struct {
 	Long       *bool
 }
 type FlavorSetFlags struct {
-	Set   *[]string
-	Unset *[]string
+	Set      *[]string
+	Unset    *[]string
+	Projects *[]string
 }
 
 type HypervisorListFlags struct {
@@ -182,7 +181,11 @@ type KeypairListFlags struct {
 	UserId *string
 }
 type KeypairCreateFlags struct {
-	Type   *string
-	PubKey *string
-	UserId *string
+	Type       *string
+	PubKey     *string
+	UserId     *string
+	Algorithm  *string
+	Bits       *int
+	PrivateKey *string
+	ServerSide *bool
 }
@@ -112,6 +112,10 @@ var imageCreate = &cobra.Command{
 			}
 			image, err = client.GetImage(image.Id)
 			utility.LogError(err, "get image failed", true)
+			if wait, timeout := common.GetWaitFlags(cmd); wait {
+				image, err = client.WaitImageStatus(image.Id, "active", timeout)
+				utility.LogError(err, "wait image active failed", true)
+			}
 		}
 		common.PrintImage(*image, false)
 	},
@@ -123,6 +127,7 @@ var imageDelete = &cobra.Command{
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		c := common.DefaultClient().GlanceV2()
+		wait, timeout := common.GetWaitFlags(cmd)
 
 		task := syncutils.TaskGroup[string]{
 			Items:        args,
@@ -140,6 +145,9 @@ var imageDelete = &cobra.Command{
 					return fmt.Errorf("delete image failed")
 				}
 				console.Info("Requested to delete image %s\n", idOrName)
+				if wait {
+					return c.WaitImageDeleted(image.Id, timeout)
+				}
 				return nil
 			},
 		}
@@ -262,5 +270,7 @@ func init() {
 		KernelId:        imageSet.Flags().String("kernel-id", "", "Set os kernel id of image"),
 	}
 
+	common.RegistryWaitFlags(imageCreate, imageDelete)
+
 	Image.AddCommand(ImageList, ImageShow, imageCreate, imageDelete, imageSave, imageSet)
 }
@@ -1,16 +1,50 @@
 package neutron
 
 import (
+	"fmt"
 	"net/url"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
 	"github.com/BytemanD/skyman/utility"
 )
 
 var agentCmd = &cobra.Command{Use: "agent", Short: "Network Agent comamnds"}
 
+type agentRouter struct {
+	neutron.Router
+	HaState string
+}
+
+// 查询 agent 上的路由器, HA 路由器需要通过 l3-agents 接口获取在该 agent 上的状态
+func listAgentRouters(client *openstack.Openstack, agentId string) ([]agentRouter, error) {
+	routers, err := client.NeutronV2().ListAgentRouters(agentId)
+	if err != nil {
+		return nil, err
+	}
+	items := []agentRouter{}
+	for _, router := range routers {
+		item := agentRouter{Router: router}
+		if router.HA {
+			agents, err := client.NeutronV2().ListRouterAgents(router.Id)
+			if err != nil {
+				console.Warn("list l3 agents of router %s failed: %s", router.Id, err)
+			}
+			if agent, ok := lo.Find(agents, func(a neutron.Agent) bool { return a.Id == agentId }); ok {
+				item.HaState = agent.HaState
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
 var agentList = &cobra.Command{
 	Use:   "list",
 	Short: "List agent",
@@ -33,10 +67,110 @@ var agentList = &cobra.Command{
 		common.PrintAgents(agents, long)
 	},
 }
+var agentShow = &cobra.Command{
+	Use:   "show <agent>",
+	Short: "Show agent",
+	Example: "network agent show <agent>\n" +
+		"network agent show <dhcp agent> --networks\n" +
+		"network agent show <l3 agent> --routers",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		networks, _ := cmd.Flags().GetBool("networks")
+		routers, _ := cmd.Flags().GetBool("routers")
+
+		agent, err := c.NeutronV2().GetAgent(args[0])
+		utility.LogIfError(err, true, "get agent %s failed", args[0])
+		switch {
+		case networks:
+			items, err := c.NeutronV2().ListAgentNetworks(agent.Id)
+			utility.LogIfError(err, true, "list networks of agent %s failed", agent.Id)
+			common.PrintNetworks(items, false)
+		case routers:
+			items, err := listAgentRouters(c, agent.Id)
+			utility.LogIfError(err, true, "list routers of agent %s failed", agent.Id)
+			common.PrintItems(
+				[]datatable.Column[agentRouter]{
+					{Name: "Id"}, {Name: "Name"},
+					{Name: "Status", AutoColor: true},
+					{Name: "HA"}, {Name: "Distributed"},
+					{Name: "HaState", AutoColor: true},
+				},
+				[]datatable.Column[agentRouter]{},
+				items, common.TableOptions{},
+			)
+		default:
+			common.PrintAgent(*agent)
+		}
+	},
+}
+var agentSet = &cobra.Command{
+	Use:   "set <agent>",
+	Short: "Set agent properties",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		params := map[string]any{}
+		if cmd.Flags().Changed("description") {
+			params["description"], _ = cmd.Flags().GetString("description")
+		}
+		for flag, value := range map[string]bool{"enable": true, "disable": false} {
+			if set, _ := cmd.Flags().GetBool(flag); set {
+				params["admin_state_up"] = value
+			}
+		}
+		if len(params) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		agent, err := c.NeutronV2().UpdateAgent(args[0], params)
+		utility.LogIfError(err, true, "update agent %s failed", args[0])
+		common.PrintAgent(*agent)
+	},
+}
+var agentDelete = &cobra.Command{
+	Use:   "delete <agent> [agent ...]",
+	Short: "Delete agent(s)",
+	Long:  "Delete agent(s), agents which are alive will be skipped unless --force is specified",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		force, _ := cmd.Flags().GetBool("force")
+		for _, id := range args {
+			agent, err := c.NeutronV2().GetAgent(id)
+			if err != nil {
+				utility.LogError(err, fmt.Sprintf("get agent %s failed", id), false)
+				continue
+			}
+			if agent.Alive && !force {
+				console.Warn("agent %s (%s on %s) is alive, skip", id, agent.Binary, agent.Host)
+				continue
+			}
+			fmt.Printf("Reqeust to delete agent %s\n", id)
+			err = c.NeutronV2().DeleteAgent(agent.Id)
+			utility.LogIfError(err, false, "delete agent %s failed", id)
+		}
+	},
+}
 
 func init() {
 	agentList.Flags().String("host", "", "filter by host")
 	agentList.Flags().String("binary", "", "filter by binary")
-	agentCmd.AddCommand(agentList)
+
+	agentShow.Flags().Bool("networks", false, "List networks hosted by the DHCP agent")
+	agentShow.Flags().Bool("routers", false, "List routers hosted by the L3 agent")
+	agentShow.MarkFlagsMutuallyExclusive("networks", "routers")
+
+	agentSet.Flags().String("description", "", "New description of the agent")
+	agentSet.Flags().Bool("enable", false, "Enable agent")
+	agentSet.Flags().Bool("disable", false, "Disable agent")
+	agentSet.MarkFlagsMutuallyExclusive("enable", "disable")
+
+	agentDelete.Flags().Bool("force", false, "Delete agents even if they are alive")
+
+	agentCmd.AddCommand(agentList, agentShow, agentSet, agentDelete)
 	Network.AddCommand(agentCmd)
 }
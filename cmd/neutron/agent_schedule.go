@@ -0,0 +1,128 @@
+package neutron
+
+import (
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
+	"github.com/BytemanD/skyman/utility"
+)
+
+// 返回需要移除的 agent, 未指定 fromAgent 时移除除目标 agent 以外的所有 agent
+func agentsToRemove(agents []neutron.Agent, toAgent string, fromAgent string) []string {
+	ids := lo.Map(agents, func(a neutron.Agent, _ int) string { return a.Id })
+	if fromAgent != "" {
+		return lo.Filter([]string{fromAgent}, func(id string, _ int) bool {
+			return id != toAgent && lo.Contains(ids, id)
+		})
+	}
+	return lo.Without(ids, toAgent)
+}
+
+var routerMove = &cobra.Command{
+	Use:   "move <router>",
+	Short: "Move router to another L3 agent",
+	Long: "Move router to another L3 agent.\n" +
+		"Non-HA router is removed from the old agent before it is added to the new agent,\n" +
+		"HA router is added to the new agent first.",
+	Example: "router move <router> --to-agent <agent>\n" +
+		"router move <router> --to-agent <agent> --from-agent <agent>",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient().NeutronV2()
+
+		toAgentId, _ := cmd.Flags().GetString("to-agent")
+		fromAgentId, _ := cmd.Flags().GetString("from-agent")
+
+		router, err := c.FindRouter(args[0])
+		utility.LogIfError(err, true, "get router %s failed", args[0])
+		toAgent, err := c.GetAgent(toAgentId)
+		utility.LogIfError(err, true, "get agent %s failed", toAgentId)
+		agents, err := c.ListRouterAgents(router.Id)
+		utility.LogIfError(err, true, "list l3 agents of router %s failed", router.Id)
+
+		hosted := lo.ContainsBy(agents, func(a neutron.Agent) bool { return a.Id == toAgent.Id })
+		removeAgents := agentsToRemove(agents, toAgent.Id, fromAgentId)
+		if hosted && len(removeAgents) == 0 {
+			console.Info("router %s is already hosted by agent %s", args[0], toAgent.Host)
+			return
+		}
+		addToAgent := func() {
+			if hosted {
+				return
+			}
+			console.Info("adding router %s to agent %s (%s)", args[0], toAgent.Id, toAgent.Host)
+			if err := c.AddAgentRouter(toAgent.Id, router.Id); err != nil {
+				if !router.HA {
+					// 非 HA 路由器已经从原 agent 移除, 尝试恢复
+					for _, agentId := range removeAgents {
+						c.AddAgentRouter(agentId, router.Id)
+					}
+				}
+				utility.LogIfError(err, true, "add router %s to agent %s failed", args[0], toAgent.Id)
+			}
+		}
+		if router.HA {
+			addToAgent()
+		}
+		for _, agentId := range removeAgents {
+			console.Info("removing router %s from agent %s", args[0], agentId)
+			err := c.RemoveAgentRouter(agentId, router.Id)
+			utility.LogIfError(err, true, "remove router %s from agent %s failed", args[0], agentId)
+		}
+		if !router.HA {
+			addToAgent()
+		}
+		agents, err = c.ListRouterAgents(router.Id)
+		utility.LogIfError(err, true, "list l3 agents of router %s failed", router.Id)
+		common.PrintAgents(agents, router.HA)
+	},
+}
+
+var networkMove = &cobra.Command{
+	Use:   "move <network>",
+	Short: "Move network to another DHCP agent",
+	Example: "network move <network> --to-agent <agent>\n" +
+		"network move <network> --to-agent <agent> --from-agent <agent>",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient().NeutronV2()
+
+		toAgentId, _ := cmd.Flags().GetString("to-agent")
+		fromAgentId, _ := cmd.Flags().GetString("from-agent")
+
+		network, err := c.FindNetwork(args[0])
+		utility.LogIfError(err, true, "get network %s failed", args[0])
+		toAgent, err := c.GetAgent(toAgentId)
+		utility.LogIfError(err, true, "get agent %s failed", toAgentId)
+		agents, err := c.ListNetworkAgents(network.Id)
+		utility.LogIfError(err, true, "list dhcp agents of network %s failed", network.Id)
+
+		if !lo.ContainsBy(agents, func(a neutron.Agent) bool { return a.Id == toAgent.Id }) {
+			console.Info("adding network %s to agent %s (%s)", args[0], toAgent.Id, toAgent.Host)
+			err := c.AddAgentNetwork(toAgent.Id, network.Id)
+			utility.LogIfError(err, true, "add network %s to agent %s failed", args[0], toAgent.Id)
+		}
+		for _, agentId := range agentsToRemove(agents, toAgent.Id, fromAgentId) {
+			console.Info("removing network %s from agent %s", args[0], agentId)
+			err := c.RemoveAgentNetwork(agentId, network.Id)
+			utility.LogIfError(err, true, "remove network %s from agent %s failed", args[0], agentId)
+		}
+		agents, err = c.ListNetworkAgents(network.Id)
+		utility.LogIfError(err, true, "list dhcp agents of network %s failed", network.Id)
+		common.PrintAgents(agents, false)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{routerMove, networkMove} {
+		cmd.Flags().String("to-agent", "", "ID of the agent to move to")
+		cmd.Flags().String("from-agent", "", "ID of the agent to move from, default: all other agents")
+		cmd.MarkFlagRequired("to-agent")
+	}
+
+	Router.AddCommand(routerMove)
+	Network.AddCommand(networkMove)
+}
@@ -0,0 +1,319 @@
+package neutron
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
+	"github.com/BytemanD/skyman/utility"
+)
+
+var ipAvailability = &cobra.Command{Use: "ip-availability", Short: "Network IP availability commands"}
+
+type ipRange struct {
+	Start netip.Addr
+	End   netip.Addr
+}
+
+func (r ipRange) String() string {
+	if r.Start == r.End {
+		return r.Start.String()
+	}
+	return fmt.Sprintf("%s-%s", r.Start, r.End)
+}
+func (r ipRange) Size() *big.Int {
+	start, end := r.Start.As16(), r.End.As16()
+	size := new(big.Int).Sub(new(big.Int).SetBytes(end[:]), new(big.Int).SetBytes(start[:]))
+	return size.Add(size, big.NewInt(1))
+}
+
+// 子网的地址池以及已分配的地址
+type subnetAllocation struct {
+	Subnet neutron.Subnet
+	Pools  []ipRange
+	Used   []netip.Addr
+}
+
+// 地址池中未分配的地址段
+func (a subnetAllocation) FreeRanges() []ipRange {
+	ranges := []ipRange{}
+	for _, pool := range a.Pools {
+		next := pool.Start
+		for _, addr := range a.Used {
+			if addr.Less(pool.Start) || pool.End.Less(addr) || addr.Less(next) {
+				continue
+			}
+			if next.Less(addr) {
+				ranges = append(ranges, ipRange{Start: next, End: addr.Prev()})
+			}
+			if next = addr.Next(); !next.IsValid() {
+				break
+			}
+		}
+		if next.IsValid() && !pool.End.Less(next) {
+			ranges = append(ranges, ipRange{Start: next, End: pool.End})
+		}
+	}
+	return ranges
+}
+
+// 查询子网和端口, 按子网统计地址池和已分配的地址
+func listSubnetAllocations(client *openstack.Openstack, query url.Values) (map[string]*subnetAllocation, error) {
+	subnets, err := client.NeutronV2().ListSubnet(query)
+	if err != nil {
+		return nil, fmt.Errorf("list subnets failed: %w", err)
+	}
+	ports, err := client.NeutronV2().ListPort(query)
+	if err != nil {
+		return nil, fmt.Errorf("list ports failed: %w", err)
+	}
+	allocations := map[string]*subnetAllocation{}
+	for _, subnet := range subnets {
+		allocation := &subnetAllocation{Subnet: subnet, Pools: []ipRange{}, Used: []netip.Addr{}}
+		for _, pool := range subnet.AllocationPools {
+			start, err1 := netip.ParseAddr(pool.Start)
+			end, err2 := netip.ParseAddr(pool.End)
+			if err1 != nil || err2 != nil {
+				console.Warn("invalid allocation pool %s-%s of subnet %s", pool.Start, pool.End, subnet.Id)
+				continue
+			}
+			allocation.Pools = append(allocation.Pools, ipRange{Start: start, End: end})
+		}
+		allocations[subnet.Id] = allocation
+	}
+	for _, port := range ports {
+		for _, fixedIp := range port.FixedIps {
+			allocation, ok := allocations[fixedIp.SubnetId]
+			if !ok {
+				continue
+			}
+			if addr, err := netip.ParseAddr(fixedIp.IpAddress); err == nil {
+				allocation.Used = append(allocation.Used, addr)
+			}
+		}
+	}
+	for _, allocation := range allocations {
+		slices.SortFunc(allocation.Used, func(a, b netip.Addr) int { return a.Compare(b) })
+		allocation.Used = slices.Compact(allocation.Used)
+	}
+	return allocations, nil
+}
+
+// 未启用 network-ip-availability 扩展时, 根据子网地址池和端口的 fixed ip 计算地址使用情况
+func computeIpAvailabilities(networks []neutron.Network, allocations map[string]*subnetAllocation, ipVersion int,
+) []neutron.NetworkIpAvailability {
+	items := []neutron.NetworkIpAvailability{}
+	for _, network := range networks {
+		item := neutron.NetworkIpAvailability{
+			NetworkId: network.Id, NetworkName: network.Name, ProjectId: network.ProjectId,
+			TotalIps: big.NewInt(0), SubnetIpAvailability: []neutron.SubnetIpAvailability{},
+		}
+		for _, subnetId := range network.Subnets {
+			allocation, ok := allocations[subnetId]
+			if !ok || (ipVersion != 0 && allocation.Subnet.IpVersion != ipVersion) {
+				continue
+			}
+			subnetItem := neutron.SubnetIpAvailability{
+				SubnetId: subnetId, SubnetName: allocation.Subnet.Name,
+				Cidr: allocation.Subnet.Cidr, IpVersion: allocation.Subnet.IpVersion,
+				TotalIps: big.NewInt(0), UsedIps: int64(len(allocation.Used)),
+			}
+			for _, pool := range allocation.Pools {
+				subnetItem.TotalIps.Add(subnetItem.TotalIps, pool.Size())
+			}
+			item.TotalIps.Add(item.TotalIps, subnetItem.TotalIps)
+			item.UsedIps += subnetItem.UsedIps
+			item.SubnetIpAvailability = append(item.SubnetIpAvailability, subnetItem)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+type subnetIpUsage struct {
+	neutron.SubnetIpAvailability
+	NetworkId   string   `json:"network_id"`
+	NetworkName string   `json:"network_name"`
+	Usage       float64  `json:"usage"`
+	FreeRanges  []string `json:"free_ranges,omitempty"`
+}
+
+func toSubnetIpUsages(items []neutron.NetworkIpAvailability) []subnetIpUsage {
+	usages := []subnetIpUsage{}
+	for _, item := range items {
+		for _, subnet := range item.SubnetIpAvailability {
+			usages = append(usages, subnetIpUsage{
+				SubnetIpAvailability: subnet,
+				NetworkId:            item.NetworkId, NetworkName: item.NetworkName,
+				Usage: subnet.Usage(),
+			})
+		}
+	}
+	return usages
+}
+
+func printSubnetIpUsages(usages []subnetIpUsage, threshold float64, freeRanges bool) {
+	columns := []datatable.Column[subnetIpUsage]{
+		{Name: "Network", RenderFunc: func(item subnetIpUsage) any {
+			return lo.CoalesceOrEmpty(item.NetworkName, item.NetworkId)
+		}},
+		{Name: "Subnet", RenderFunc: func(item subnetIpUsage) any {
+			return lo.CoalesceOrEmpty(item.SubnetName, item.SubnetId)
+		}},
+		{Name: "Cidr"},
+		{Name: "UsedIps", Text: "Used IPs"},
+		{Name: "TotalIps", Text: "Total IPs", RenderFunc: func(item subnetIpUsage) any {
+			if item.TotalIps == nil {
+				return ""
+			}
+			return item.TotalIps.String()
+		}},
+		{Name: "Usage", RenderFunc: func(item subnetIpUsage) any {
+			usage := fmt.Sprintf("%.1f%%", item.Usage)
+			if item.Usage >= threshold {
+				return utility.RedString(usage)
+			}
+			return usage
+		}},
+	}
+	if freeRanges {
+		columns = append(columns, datatable.Column[subnetIpUsage]{
+			Name: "FreeRanges", RenderFunc: func(item subnetIpUsage) any {
+				return strings.Join(item.FreeRanges, "\n")
+			},
+		})
+	}
+	common.PrintItems(columns, []datatable.Column[subnetIpUsage]{}, usages, common.TableOptions{})
+
+	if common.CONF.Format == common.JSON || common.CONF.Format == common.YAML {
+		return
+	}
+	overloaded := lo.Filter(usages, func(item subnetIpUsage, _ int) bool { return item.Usage >= threshold })
+	if len(overloaded) > 0 {
+		console.Warn("%d subnet(s) usage >= %.1f%%: %s", len(overloaded), threshold,
+			strings.Join(lo.Map(overloaded, func(item subnetIpUsage, _ int) string {
+				return lo.CoalesceOrEmpty(item.SubnetName, item.SubnetId)
+			}), ", "))
+	}
+}
+
+var ipAvailabilityList = &cobra.Command{
+	Use:   "list",
+	Short: "List IP availability of subnets",
+	Long: "List IP availability of subnets.\n" +
+		"If network-ip-availability extension is not enabled, used and total IPs are computed\n" +
+		"from fixed IPs of ports and allocation pools of subnets.",
+	Example: "network ip-availability list\n" +
+		"network ip-availability list --network net1 --threshold 90",
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := common.DefaultClient()
+
+		networkIdOrName, _ := cmd.Flags().GetString("network")
+		project, _ := cmd.Flags().GetString("project")
+		ipVersion, _ := cmd.Flags().GetInt("ip-version")
+		threshold, _ := cmd.Flags().GetFloat64("threshold")
+		compute, _ := cmd.Flags().GetBool("compute")
+
+		query := url.Values{}
+		networks := []neutron.Network{}
+		if networkIdOrName != "" {
+			network, err := c.NeutronV2().FindNetwork(networkIdOrName)
+			utility.LogIfError(err, true, "get network %s failed", networkIdOrName)
+			networks = append(networks, *network)
+			query.Set("network_id", network.Id)
+		}
+		if project != "" {
+			query.Set("project_id", project)
+		}
+		if ipVersion != 0 {
+			query.Set("ip_version", fmt.Sprintf("%d", ipVersion))
+		}
+
+		var items []neutron.NetworkIpAvailability
+		var err error
+		if !compute {
+			if items, err = c.NeutronV2().ListNetworkIpAvailability(query); err != nil {
+				console.Warn("list network ip availabilities failed, compute from ports: %s", err)
+			}
+		}
+		if compute || err != nil {
+			if networkIdOrName == "" {
+				networkQuery := url.Values{}
+				if project != "" {
+					networkQuery.Set("project_id", project)
+				}
+				networks, err = c.NeutronV2().ListNetwork(networkQuery)
+				utility.LogIfError(err, true, "list networks failed")
+			}
+			allocationQuery := url.Values{}
+			if len(networks) == 1 && networkIdOrName != "" {
+				allocationQuery.Set("network_id", networks[0].Id)
+			}
+			allocations, err := listSubnetAllocations(c, allocationQuery)
+			utility.LogIfError(err, true, "compute ip availabilities failed")
+			items = computeIpAvailabilities(networks, allocations, ipVersion)
+		}
+		printSubnetIpUsages(toSubnetIpUsages(items), threshold, false)
+	},
+}
+var ipAvailabilityShow = &cobra.Command{
+	Use:   "show <network>",
+	Short: "Show IP availability and free IP ranges of network",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		threshold, _ := cmd.Flags().GetFloat64("threshold")
+		compute, _ := cmd.Flags().GetBool("compute")
+
+		network, err := c.NeutronV2().FindNetwork(args[0])
+		utility.LogIfError(err, true, "get network %s failed", args[0])
+		allocations, err := listSubnetAllocations(c, url.Values{"network_id": []string{network.Id}})
+		utility.LogIfError(err, true, "list allocations of network %s failed", args[0])
+
+		var item *neutron.NetworkIpAvailability
+		if !compute {
+			if item, err = c.NeutronV2().GetNetworkIpAvailability(network.Id); err != nil {
+				console.Warn("get network ip availability failed, compute from ports: %s", err)
+			}
+		}
+		if compute || err != nil {
+			item = &computeIpAvailabilities([]neutron.Network{*network}, allocations, 0)[0]
+		}
+		usages := toSubnetIpUsages([]neutron.NetworkIpAvailability{*item})
+		for i, usage := range usages {
+			if allocation, ok := allocations[usage.SubnetId]; ok {
+				usages[i].FreeRanges = lo.Map(allocation.FreeRanges(), func(r ipRange, _ int) string {
+					return r.String()
+				})
+			}
+		}
+		printSubnetIpUsages(usages, threshold, true)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{ipAvailabilityList, ipAvailabilityShow} {
+		cmd.Flags().Float64("threshold", 80, "Highlight subnets whose usage (percent) is above the threshold")
+		cmd.Flags().Bool("compute", false,
+			"Compute from ports and allocation pools instead of using network-ip-availabilities API")
+	}
+	ipAvailabilityList.Flags().String("network", "", "Filter by network (name or ID)")
+	ipAvailabilityList.Flags().String("project", "", "Filter by project ID")
+	ipAvailabilityList.Flags().Int("ip-version", 0, "Filter by IP version, 4 or 6")
+
+	ipAvailability.AddCommand(ipAvailabilityList, ipAvailabilityShow)
+	Network.AddCommand(ipAvailability)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
 	"github.com/BytemanD/skyman/utility"
 	"github.com/spf13/cobra"
@@ -57,21 +58,69 @@ var networkShow = &cobra.Command{
 		common.PrintNetwork(*network)
 	},
 }
+
+// provider 以及 router:external 等属性, 创建和更新网络时通用
+func registerNetworkFlags(cmd *cobra.Command) {
+	cmd.Flags().String("provider-network-type", "", "Physical mechanism of the network, e.g. flat, vlan, vxlan, geneve")
+	cmd.Flags().String("provider-physical-network", "", "Name of the physical network")
+	cmd.Flags().Int("provider-segment", 0, "VLAN ID for VLAN networks or VNI for VXLAN/GENEVE networks")
+	cmd.Flags().Bool("external", false, "Set the network as external")
+	cmd.Flags().Bool("internal", false, "Set the network as internal")
+	cmd.Flags().Bool("shared", false, "Share the network between projects")
+	cmd.Flags().Bool("no-share", false, "Do not share the network between projects")
+	cmd.Flags().Int("mtu", 0, "MTU of the network")
+	cmd.Flags().Bool("port-security", true, "Enable port security by default for ports on the network, "+
+		"use --port-security=false to disable")
+	cmd.MarkFlagsMutuallyExclusive("external", "internal")
+	cmd.MarkFlagsMutuallyExclusive("shared", "no-share")
+}
+func getNetworkParams(cmd *cobra.Command) map[string]any {
+	params := map[string]any{}
+	if networkType, _ := cmd.Flags().GetString("provider-network-type"); networkType != "" {
+		params["provider:network_type"] = networkType
+	}
+	if physicalNetwork, _ := cmd.Flags().GetString("provider-physical-network"); physicalNetwork != "" {
+		params["provider:physical_network"] = physicalNetwork
+	}
+	if cmd.Flags().Changed("provider-segment") {
+		params["provider:segmentation_id"], _ = cmd.Flags().GetInt("provider-segment")
+	}
+	for flag, value := range map[string]bool{"external": true, "internal": false} {
+		if set, _ := cmd.Flags().GetBool(flag); set {
+			params["router:external"] = value
+		}
+	}
+	for flag, value := range map[string]bool{"shared": true, "no-share": false} {
+		if set, _ := cmd.Flags().GetBool(flag); set {
+			params["shared"] = value
+		}
+	}
+	if cmd.Flags().Changed("mtu") {
+		params["mtu"], _ = cmd.Flags().GetInt("mtu")
+	}
+	if cmd.Flags().Changed("port-security") {
+		params["port_security_enabled"], _ = cmd.Flags().GetBool("port-security")
+	}
+	return params
+}
+
 var networkCreate = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create network",
-	Args:  cobra.ExactArgs(1),
+	Example: "network create net1\n" +
+		"network create net1 --provider-network-type vlan --provider-physical-network physnet1 --provider-segment 100\n" +
+		"network create public --external --shared --provider-network-type flat --provider-physical-network physnet1",
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		c := common.DefaultClient().NeutronV2()
 
 		// name, _ := cmd.Flags().GetString("name")
 		disable, _ := cmd.Flags().GetBool("disable")
 		description, _ := cmd.Flags().GetString("description")
-		params := map[string]any{
-			"name": args[0],
-		}
+		params := getNetworkParams(cmd)
+		params["name"] = args[0]
 		if disable {
-			params["enable"] = false
+			params["admin_state_up"] = false
 		}
 		if description != "" {
 			params["description"] = description
@@ -82,13 +131,56 @@ var networkCreate = &cobra.Command{
 	},
 }
 
+var networkSet = &cobra.Command{
+	Use:   "set <network>",
+	Short: "Set network properties",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := common.DefaultClient()
+
+		params := getNetworkParams(cmd)
+		if cmd.Flags().Changed("name") {
+			params["name"], _ = cmd.Flags().GetString("name")
+		}
+		if cmd.Flags().Changed("description") {
+			params["description"], _ = cmd.Flags().GetString("description")
+		}
+		for flag, value := range map[string]bool{"enable": true, "disable": false} {
+			if set, _ := cmd.Flags().GetBool(flag); set {
+				params["admin_state_up"] = value
+			}
+		}
+		if policyId, changed := getQosPolicyFlag(cmd, client); changed {
+			params["qos_policy_id"] = policyId
+		}
+		if len(params) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		network, err := client.NeutronV2().FindNetwork(args[0])
+		utility.LogError(err, "show network failed", true)
+		network, err = client.NeutronV2().UpdateNetwork(network.Id, params)
+		utility.LogError(err, "update network failed", true)
+		common.PrintNetwork(*network)
+	},
+}
+
 func init() {
 	networkList.Flags().BoolP("long", "l", false, "List additional fields in output")
 	networkList.Flags().StringP("name", "n", "", "Search by router name")
 
 	networkCreate.Flags().String("description", "", "Set network description")
-	networkCreate.Flags().Bool("disable", false, "Disable router")
+	networkCreate.Flags().Bool("disable", false, "Disable network")
+	registerNetworkFlags(networkCreate)
+
+	networkSet.Flags().String("name", "", "New name of the network")
+	networkSet.Flags().String("description", "", "New description of the network")
+	networkSet.Flags().Bool("enable", false, "Enable network")
+	networkSet.Flags().Bool("disable", false, "Disable network")
+	networkSet.MarkFlagsMutuallyExclusive("enable", "disable")
+	registerNetworkFlags(networkSet)
+	registerQosPolicyFlags(networkSet)
 
-	Network.AddCommand(networkList, networkShow, networkDelete, networkCreate)
+	Network.AddCommand(networkList, networkShow, networkDelete, networkCreate, networkSet)
 	// Network.AddCommand(agentCmd)
 }
@@ -2,12 +2,15 @@ package neutron
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
 	"github.com/BytemanD/easygo/pkg/syncutils"
 	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack"
 	"github.com/BytemanD/skyman/openstack/model/neutron"
 	"github.com/BytemanD/skyman/utility"
 )
@@ -37,13 +40,33 @@ var portList = &cobra.Command{
 		device_id, _ := cmd.Flags().GetString("device-id")
 		host, _ := cmd.Flags().GetString("host")
 		noHost, _ := cmd.Flags().GetBool("no-host")
+		deviceOwner, _ := cmd.Flags().GetString("device-owner")
+		status, _ := cmd.Flags().GetString("status")
+		macAddress, _ := cmd.Flags().GetString("mac-address")
+		fixedIpValues, _ := cmd.Flags().GetStringArray("fixed-ip")
 
-		ports, err := c.ListPort(utility.UrlValues(map[string]string{
+		query := utility.UrlValues(map[string]string{
 			"name":            name,
 			"network_id":      network,
 			"device_id":       device_id,
 			"binding:host_id": host,
-		}))
+			"device_owner":    deviceOwner,
+			"status":          strings.ToUpper(status),
+			"mac_address":     macAddress,
+		})
+		if len(fixedIpValues) > 0 {
+			fixedIps, err := parseFixedIps(common.DefaultClient(), fixedIpValues)
+			utility.LogError(err, "invalid fixed ip", true)
+			for _, fixedIp := range fixedIps {
+				if fixedIp.SubnetId != "" {
+					query.Add("fixed_ips", "subnet_id="+fixedIp.SubnetId)
+				}
+				if fixedIp.IpAddress != "" {
+					query.Add("fixed_ips", "ip_address="+fixedIp.IpAddress)
+				}
+			}
+		}
+		ports, err := c.ListPort(query)
 		utility.LogError(err, "list ports failed", true)
 		if noHost {
 			filteredPort := []neutron.Port{}
@@ -76,6 +99,7 @@ var portDelete = &cobra.Command{
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		force, _ := cmd.Flags().GetBool("force")
+		wait, timeout := common.GetWaitFlags(cmd)
 		c := common.DefaultClient().NeutronV2()
 		syncutils.StartTasks(
 			syncutils.TaskOption{
@@ -97,15 +121,222 @@ var portDelete = &cobra.Command{
 				err = c.DeletePort(port.Id)
 				if err != nil {
 					utility.LogError(err, fmt.Sprintf("Delete port %s failed", item), false)
-				} else {
-					console.Info("Delete port %s success", item)
+					return nil
 				}
+				if wait {
+					if err := c.WaitPortDeleted(port.Id, timeout); err != nil {
+						return err
+					}
+				}
+				console.Info("Delete port %s success", item)
 				return nil
 			},
 		)
 	},
 }
 
+// 根据参数生成创建或更新端口的参数, 更新时 port 为当前的端口, 列表类的参数追加到已有的值中
+func getPortParams(cmd *cobra.Command, client *openstack.Openstack, port *neutron.Port) (map[string]any, error) {
+	vnicType, _ := cmd.Flags().GetString("vnic-type")
+	host, _ := cmd.Flags().GetString("host")
+	fixedIpValues, _ := cmd.Flags().GetStringArray("fixed-ip")
+	profileValues, _ := cmd.Flags().GetStringArray("binding-profile")
+	securityGroups, _ := cmd.Flags().GetStringArray("security-group")
+	noSecurityGroup, _ := cmd.Flags().GetBool("no-security-group")
+	allowedAddresses, _ := cmd.Flags().GetStringArray("allowed-address")
+	noAllowedAddress, _ := cmd.Flags().GetBool("no-allowed-address")
+	enablePortSecurity, _ := cmd.Flags().GetBool("enable-port-security")
+	disablePortSecurity, _ := cmd.Flags().GetBool("disable-port-security")
+
+	params := map[string]any{}
+	if cmd.Flags().Changed("description") {
+		params["description"], _ = cmd.Flags().GetString("description")
+	}
+	if vnicType != "" {
+		if !lo.Contains(vnicTypes, vnicType) {
+			return nil, fmt.Errorf("invalid vnic type %s, supported: %v", vnicType, vnicTypes)
+		}
+		params["binding:vnic_type"] = vnicType
+	}
+	if host != "" {
+		params["binding:host_id"] = host
+	}
+	if len(profileValues) > 0 {
+		profile, err := parseBindingProfile(profileValues)
+		if err != nil {
+			return nil, err
+		}
+		if port != nil {
+			profile = lo.Assign(port.BindingProfile, profile)
+		}
+		params["binding:profile"] = profile
+	}
+	if len(fixedIpValues) > 0 {
+		fixedIps, err := parseFixedIps(client, fixedIpValues)
+		if err != nil {
+			return nil, err
+		}
+		if port != nil {
+			fixedIps = append(port.FixedIps, fixedIps...)
+		}
+		params["fixed_ips"] = fixedIps
+	}
+	if len(securityGroups) > 0 || noSecurityGroup {
+		sgIds, err := findSecurityGroupIds(client, securityGroups)
+		if err != nil {
+			return nil, err
+		}
+		if port != nil && !noSecurityGroup {
+			sgIds = lo.Union(port.SecurityGroups, sgIds)
+		}
+		params["security_groups"] = sgIds
+	}
+	if len(allowedAddresses) > 0 || noAllowedAddress {
+		pairs, err := parseAllowedAddresses(allowedAddresses)
+		if err != nil {
+			return nil, err
+		}
+		if port != nil && !noAllowedAddress {
+			pairs = append(port.AllowedAddressPairs, pairs...)
+		}
+		params["allowed_address_pairs"] = pairs
+	}
+	switch {
+	case enablePortSecurity:
+		params["port_security_enabled"] = true
+	case disablePortSecurity:
+		params["port_security_enabled"] = false
+	}
+	if policyId, changed := getQosPolicyFlag(cmd, client); changed {
+		params["qos_policy_id"] = policyId
+	}
+	return params, nil
+}
+
+var portCreate = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create port",
+	Example: "port create port1 --network net1\n" +
+		"port create port1 --network net1 --fixed-ip subnet=subnet1,ip-address=192.168.1.10\n" +
+		"port create vf1 --network net1 --vnic-type direct --binding-profile trusted=true\n" +
+		"port create vip1 --network net1 --allowed-address ip=192.168.1.100 --security-group default",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := common.DefaultClient()
+
+		networkIdOrName, _ := cmd.Flags().GetString("network")
+		macAddress, _ := cmd.Flags().GetString("mac-address")
+		disable, _ := cmd.Flags().GetBool("disable")
+
+		network, err := client.NeutronV2().FindNetwork(networkIdOrName)
+		utility.LogIfError(err, true, "get network %s failed", networkIdOrName)
+		params, err := getPortParams(cmd, client, nil)
+		utility.LogError(err, "invalid arguments", true)
+		params["name"] = args[0]
+		params["network_id"] = network.Id
+		if macAddress != "" {
+			params["mac_address"] = macAddress
+		}
+		if disable {
+			params["admin_state_up"] = false
+		}
+		port, err := client.NeutronV2().CreatePort(params)
+		utility.LogError(err, "create port failed", true)
+		common.PrintPort(*port)
+	},
+}
+var portSet = &cobra.Command{
+	Use:   "set <port>",
+	Short: "Set port properties",
+	Example: "port set port1 --fixed-ip subnet=subnet1\n" +
+		"port set port1 --no-security-group --security-group sg1\n" +
+		"port set port1 --allowed-address ip=192.168.1.100,mac=fa:16:3e:00:00:01\n" +
+		"port set port1 --qos-policy policy1",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := common.DefaultClient()
+
+		port, err := client.NeutronV2().FindPort(args[0])
+		utility.LogError(err, "show port failed", true)
+		params, err := getPortParams(cmd, client, port)
+		utility.LogError(err, "invalid arguments", true)
+		if cmd.Flags().Changed("name") {
+			params["name"], _ = cmd.Flags().GetString("name")
+		}
+		for flag, value := range map[string]bool{"enable": true, "disable": false} {
+			if set, _ := cmd.Flags().GetBool(flag); set {
+				params["admin_state_up"] = value
+			}
+		}
+		if len(params) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		port, err = client.NeutronV2().UpdatePort(port.Id, params)
+		utility.LogError(err, "update port failed", true)
+		common.PrintPort(*port)
+	},
+}
+var portUnset = &cobra.Command{
+	Use:   "unset <port>",
+	Short: "Unset port properties",
+	Example: "port unset port1 --fixed-ip ip-address=192.168.1.10\n" +
+		"port unset port1 --security-group sg1 --allowed-address ip=192.168.1.100\n" +
+		"port unset port1 --binding-profile trusted --qos-policy",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := common.DefaultClient()
+
+		fixedIpValues, _ := cmd.Flags().GetStringArray("fixed-ip")
+		securityGroups, _ := cmd.Flags().GetStringArray("security-group")
+		allowedAddresses, _ := cmd.Flags().GetStringArray("allowed-address")
+		profileKeys, _ := cmd.Flags().GetStringArray("binding-profile")
+		unsetQosPolicy, _ := cmd.Flags().GetBool("qos-policy")
+
+		port, err := client.NeutronV2().FindPort(args[0])
+		utility.LogError(err, "show port failed", true)
+
+		params := map[string]any{}
+		if len(fixedIpValues) > 0 {
+			fixedIps, err := parseFixedIps(client, fixedIpValues)
+			utility.LogError(err, "invalid fixed ip", true)
+			params["fixed_ips"] = lo.Reject(port.FixedIps, func(item neutron.FixedIp, _ int) bool {
+				return lo.ContainsBy(fixedIps, func(f neutron.FixedIp) bool {
+					return (f.SubnetId == "" || f.SubnetId == item.SubnetId) &&
+						(f.IpAddress == "" || f.IpAddress == item.IpAddress)
+				})
+			})
+		}
+		if len(securityGroups) > 0 {
+			sgIds, err := findSecurityGroupIds(client, securityGroups)
+			utility.LogError(err, "invalid security group", true)
+			params["security_groups"] = lo.Without(port.SecurityGroups, sgIds...)
+		}
+		if len(allowedAddresses) > 0 {
+			pairs, err := parseAllowedAddresses(allowedAddresses)
+			utility.LogError(err, "invalid allowed address", true)
+			params["allowed_address_pairs"] = lo.Reject(port.AllowedAddressPairs, func(item neutron.AddressPair, _ int) bool {
+				return lo.ContainsBy(pairs, func(p neutron.AddressPair) bool {
+					return p.IpAddress == item.IpAddress && (p.MacAddress == "" || p.MacAddress == item.MacAddress)
+				})
+			})
+		}
+		if len(profileKeys) > 0 {
+			params["binding:profile"] = lo.OmitByKeys(port.BindingProfile, profileKeys)
+		}
+		if unsetQosPolicy {
+			params["qos_policy_id"] = nil
+		}
+		if len(params) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		port, err = client.NeutronV2().UpdatePort(port.Id, params)
+		utility.LogError(err, "update port failed", true)
+		common.PrintPort(*port)
+	},
+}
+
 func init() {
 	portList.Flags().BoolP("long", "l", false, "List additional fields in output")
 	portList.Flags().StringP("name", "n", "", "Search by port name")
@@ -113,7 +344,36 @@ func init() {
 	portList.Flags().String("device-id", "", "Search by device id")
 	portList.Flags().String("host", "", "Search by binding host")
 	portList.Flags().Bool("no-host", false, "Search port with no host")
+	portList.Flags().String("device-owner", "", "Search by device owner, e.g. compute:nova, network:dhcp")
+	portList.Flags().String("status", "", "Search by port status, e.g. ACTIVE, DOWN")
+	portList.Flags().String("mac-address", "", "Search by MAC address")
+	portList.Flags().StringArray("fixed-ip", []string{}, "Search by fixed IP, format: subnet=<subnet>,ip-address=<ip-address>")
 
 	portDelete.Flags().Bool("force", false, "Force delete")
-	Port.AddCommand(portList, portShow, portDelete)
+	common.RegistryWaitFlags(portDelete)
+	registerPortCommonFlags(portCreate)
+	portCreate.Flags().String("network", "", "Network this port belongs to (name or ID)")
+	portCreate.Flags().String("mac-address", "", "MAC address of the port")
+	portCreate.Flags().Bool("disable", false, "Disable port")
+	portCreate.Flags().Bool("no-security-group", false, "Associate no security groups with the port")
+	portCreate.Flags().String("qos-policy", "", "Attach qos policy (name or ID)")
+	portCreate.MarkFlagRequired("network")
+	portCreate.MarkFlagsMutuallyExclusive("security-group", "no-security-group")
+
+	registerPortCommonFlags(portSet)
+	registerQosPolicyFlags(portSet)
+	portSet.Flags().String("name", "", "New name of the port")
+	portSet.Flags().Bool("enable", false, "Enable port")
+	portSet.Flags().Bool("disable", false, "Disable port")
+	portSet.Flags().Bool("no-security-group", false, "Clear existing security groups")
+	portSet.Flags().Bool("no-allowed-address", false, "Clear existing allowed address pairs")
+	portSet.MarkFlagsMutuallyExclusive("enable", "disable")
+
+	portUnset.Flags().StringArray("fixed-ip", []string{}, "Fixed IP to remove, format: subnet=<subnet>,ip-address=<ip-address>")
+	portUnset.Flags().StringArray("security-group", []string{}, "Security group to remove (name or ID)")
+	portUnset.Flags().StringArray("allowed-address", []string{}, "Allowed address pair to remove, format: ip=<ip-address>[,mac=<mac-address>]")
+	portUnset.Flags().StringArray("binding-profile", []string{}, "Key to remove from binding:profile")
+	portUnset.Flags().Bool("qos-policy", false, "Remove qos policy")
+
+	Port.AddCommand(portList, portShow, portDelete, portCreate, portSet, portUnset)
 }
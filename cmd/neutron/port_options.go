@@ -0,0 +1,118 @@
+package neutron
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
+)
+
+const fixedIpUsage = "Fixed IP of the port, format: subnet=<subnet>,ip-address=<ip-address>"
+const allowedAddressUsage = "Allowed address pair, format: ip=<ip-address>[,mac=<mac-address>]"
+
+var vnicTypes = []string{"normal", "direct", "direct-physical", "macvtap", "baremetal", "virtio-forwarder"}
+
+// 解析 key1=value1,key2=value2 格式的参数, keys 为每个 key 支持的别名
+func parseKeyValues(value string, keys map[string][]string) (map[string]string, error) {
+	result := map[string]string{}
+	for _, item := range strings.Split(value, ",") {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("invalid value '%s'", value)
+		}
+		key, ok := lo.FindKeyBy(keys, func(_ string, aliases []string) bool {
+			return lo.Contains(aliases, kv[0])
+		})
+		if !ok {
+			return nil, fmt.Errorf("invalid key '%s' in '%s'", kv[0], value)
+		}
+		result[key] = kv[1]
+	}
+	return result, nil
+}
+
+func parseFixedIps(client *openstack.Openstack, values []string) ([]neutron.FixedIp, error) {
+	fixedIps := []neutron.FixedIp{}
+	for _, value := range values {
+		kv, err := parseKeyValues(value, map[string][]string{
+			"subnet": {"subnet", "subnet-id"}, "ip-address": {"ip-address", "ip"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		fixedIp := neutron.FixedIp{IpAddress: kv["ip-address"]}
+		if kv["subnet"] != "" {
+			subnet, err := client.NeutronV2().FindSubnet(kv["subnet"])
+			if err != nil {
+				return nil, fmt.Errorf("get subnet %s failed: %w", kv["subnet"], err)
+			}
+			fixedIp.SubnetId = subnet.Id
+		}
+		fixedIps = append(fixedIps, fixedIp)
+	}
+	return fixedIps, nil
+}
+
+func parseAllowedAddresses(values []string) ([]neutron.AddressPair, error) {
+	pairs := []neutron.AddressPair{}
+	for _, value := range values {
+		kv, err := parseKeyValues(value, map[string][]string{
+			"ip": {"ip", "ip-address"}, "mac": {"mac", "mac-address"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if kv["ip"] == "" {
+			return nil, fmt.Errorf("ip is required in '%s'", value)
+		}
+		pairs = append(pairs, neutron.AddressPair{IpAddress: kv["ip"], MacAddress: kv["mac"]})
+	}
+	return pairs, nil
+}
+
+// 解析 binding:profile, 值为 JSON 时按 JSON 解析, 例如: trusted=true, capabilities=["switchdev"]
+func parseBindingProfile(values []string) (map[string]any, error) {
+	profile := map[string]any{}
+	for _, value := range values {
+		kv := strings.SplitN(value, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid binding profile '%s'", value)
+		}
+		var v any
+		if err := json.Unmarshal([]byte(kv[1]), &v); err != nil {
+			v = kv[1]
+		}
+		profile[kv[0]] = v
+	}
+	return profile, nil
+}
+
+func findSecurityGroupIds(client *openstack.Openstack, idOrNames []string) ([]string, error) {
+	ids := []string{}
+	for _, idOrName := range idOrNames {
+		sg, err := client.NeutronV2().FindSecurityGroup(idOrName)
+		if err != nil {
+			return nil, fmt.Errorf("get security group %s failed: %w", idOrName, err)
+		}
+		ids = append(ids, sg.Id)
+	}
+	return ids, nil
+}
+
+func registerPortCommonFlags(cmd *cobra.Command) {
+	cmd.Flags().String("description", "", "Description of the port")
+	cmd.Flags().StringArray("fixed-ip", []string{}, fixedIpUsage)
+	cmd.Flags().String("vnic-type", "", fmt.Sprintf("VNIC type, %v", vnicTypes))
+	cmd.Flags().StringArray("binding-profile", []string{}, "Custom data to be passed as binding:profile, format: <key>=<value>")
+	cmd.Flags().String("host", "", "Allocate port on host (binding:host_id)")
+	cmd.Flags().StringArray("security-group", []string{}, "Security group to associate with the port (name or ID)")
+	cmd.Flags().StringArray("allowed-address", []string{}, allowedAddressUsage)
+	cmd.Flags().Bool("enable-port-security", false, "Enable port security")
+	cmd.Flags().Bool("disable-port-security", false, "Disable port security")
+	cmd.MarkFlagsMutuallyExclusive("enable-port-security", "disable-port-security")
+}
@@ -1,9 +1,12 @@
 package neutron
 
 import (
+	"fmt"
 	"net/url"
 
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack"
 	"github.com/BytemanD/skyman/utility"
 	"github.com/spf13/cobra"
 )
@@ -47,11 +50,131 @@ var qosPolicyShow = &cobra.Command{
 	},
 }
 
+func registerQosPolicyFlags(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		cmd.Flags().String("qos-policy", "", "Attach qos policy (name or ID)")
+		cmd.Flags().Bool("no-qos-policy", false, "Remove qos policy")
+		cmd.MarkFlagsMutuallyExclusive("qos-policy", "no-qos-policy")
+	}
+}
+
+// 返回 qos_policy_id 参数的值, 未指定 --qos-policy 和 --no-qos-policy 时 changed 为 false
+func getQosPolicyFlag(cmd *cobra.Command, client *openstack.Openstack) (policyId any, changed bool) {
+	if noQosPolicy, _ := cmd.Flags().GetBool("no-qos-policy"); noQosPolicy {
+		return nil, true
+	}
+	idOrName, _ := cmd.Flags().GetString("qos-policy")
+	if idOrName == "" {
+		return nil, false
+	}
+	policy, err := client.NeutronV2().FindQosPolicy(idOrName)
+	utility.LogIfError(err, true, "get qos policy %s failed", idOrName)
+	return policy.Id, true
+}
+
+var qosPolicyCreate = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create qos policy",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		description, _ := cmd.Flags().GetString("description")
+		shared, _ := cmd.Flags().GetBool("shared")
+		isDefault, _ := cmd.Flags().GetBool("default")
+		projectIdOrName, _ := cmd.Flags().GetString("project")
+		params := map[string]any{"name": args[0]}
+		if description != "" {
+			params["description"] = description
+		}
+		if shared {
+			params["shared"] = true
+		}
+		if isDefault {
+			params["is_default"] = true
+		}
+		if projectIdOrName != "" {
+			project, err := c.KeystoneV3().FindProject(projectIdOrName)
+			utility.LogError(err, "get project failed", true)
+			params["project_id"] = project.Id
+		}
+		policy, err := c.NeutronV2().CreateQosPolicy(params)
+		utility.LogError(err, "create qos policy failed", true)
+		common.PrintQosPolicy(*policy)
+	},
+}
+var qosPolicySet = &cobra.Command{
+	Use:   "set <qos-policy>",
+	Short: "Set qos policy properties",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		params := map[string]any{}
+		if cmd.Flags().Changed("name") {
+			params["name"], _ = cmd.Flags().GetString("name")
+		}
+		if cmd.Flags().Changed("description") {
+			params["description"], _ = cmd.Flags().GetString("description")
+		}
+		for flag, value := range map[string]bool{"shared": true, "no-shared": false} {
+			if set, _ := cmd.Flags().GetBool(flag); set {
+				params["shared"] = value
+			}
+		}
+		for flag, value := range map[string]bool{"default": true, "no-default": false} {
+			if set, _ := cmd.Flags().GetBool(flag); set {
+				params["is_default"] = value
+			}
+		}
+		if len(params) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		policy, err := c.NeutronV2().FindQosPolicy(args[0])
+		utility.LogIfError(err, true, "get qos policy %s failed", args[0])
+		policy, err = c.NeutronV2().UpdateQosPolicy(policy.Id, params)
+		utility.LogError(err, "update qos policy failed", true)
+		common.PrintQosPolicy(*policy)
+	},
+}
+var qosPolicyDelete = &cobra.Command{
+	Use:   "delete <qos-policy> [qos-policy ...]",
+	Short: "Delete qos policy(s)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		for _, idOrName := range args {
+			policy, err := c.NeutronV2().FindQosPolicy(idOrName)
+			if err != nil {
+				utility.LogError(err, fmt.Sprintf("get qos policy %s failed", idOrName), false)
+				continue
+			}
+			fmt.Printf("Reqeust to delete qos policy %s\n", idOrName)
+			err = c.NeutronV2().DeleteQosPolicy(policy.Id)
+			utility.LogIfError(err, false, "delete qos policy %s failed", idOrName)
+		}
+	},
+}
+
 func init() {
 	qosPolicyList.Flags().StringP("project", "", "", "List according to the project")
 
-	policy.AddCommand(qosPolicyList, qosPolicyShow)
-	Qos.AddCommand(policy)
+	qosPolicyCreate.Flags().String("description", "", "Description of the qos policy")
+	qosPolicyCreate.Flags().Bool("shared", false, "Make the qos policy accessible to other projects")
+	qosPolicyCreate.Flags().Bool("default", false, "Set as the default qos policy of the project")
+	qosPolicyCreate.Flags().String("project", "", "Owner's project (name or ID)")
 
-	Network.AddCommand(Qos)
+	qosPolicySet.Flags().String("name", "", "New name of the qos policy")
+	qosPolicySet.Flags().String("description", "", "New description of the qos policy")
+	qosPolicySet.Flags().Bool("shared", false, "Make the qos policy accessible to other projects")
+	qosPolicySet.Flags().Bool("no-shared", false, "Make the qos policy not accessible to other projects")
+	qosPolicySet.Flags().Bool("default", false, "Set as the default qos policy of the project")
+	qosPolicySet.Flags().Bool("no-default", false, "Set as a non-default qos policy")
+	qosPolicySet.MarkFlagsMutuallyExclusive("shared", "no-shared")
+	qosPolicySet.MarkFlagsMutuallyExclusive("default", "no-default")
+
+	policy.AddCommand(qosPolicyList, qosPolicyShow, qosPolicyCreate, qosPolicySet, qosPolicyDelete)
+	Qos.AddCommand(policy)
 }
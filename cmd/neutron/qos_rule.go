@@ -1,11 +1,32 @@
 package neutron
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
 	"github.com/BytemanD/skyman/utility"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
 
+const (
+	QOS_RULE_BANDWIDTH_LIMIT   = "bandwidth-limit"
+	QOS_RULE_DSCP_MARKING      = "dscp-marking"
+	QOS_RULE_MINIMUM_BANDWIDTH = "minimum-bandwidth"
+)
+
+var qosRuleTypes = []string{QOS_RULE_BANDWIDTH_LIMIT, QOS_RULE_DSCP_MARKING, QOS_RULE_MINIMUM_BANDWIDTH}
+
+// 每种规则类型必须指定的参数
+var qosRuleRequiredFlags = map[string]string{
+	QOS_RULE_BANDWIDTH_LIMIT:   "max-kbps",
+	QOS_RULE_DSCP_MARKING:      "dscp-mark",
+	QOS_RULE_MINIMUM_BANDWIDTH: "min-kbps",
+}
+
 var qosRule = &cobra.Command{Use: "rule"}
 
 var qosRuleList = &cobra.Command{
@@ -21,8 +42,86 @@ var qosRuleList = &cobra.Command{
 		common.PrintQosPolicyRules(policy.Rules, false)
 	},
 }
+var qosRuleCreate = &cobra.Command{
+	Use:   "create <qos-policy>",
+	Short: "Create qos rule",
+	Example: "qos rule create policy1 --type bandwidth-limit --max-kbps 10240 --max-burst-kbps 1024\n" +
+		"qos rule create policy1 --type bandwidth-limit --max-kbps 10240 --direction ingress\n" +
+		"qos rule create policy1 --type dscp-marking --dscp-mark 26\n" +
+		"qos rule create policy1 --type minimum-bandwidth --min-kbps 1024",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ruleType, _ := cmd.Flags().GetString("type")
+		if !lo.Contains(qosRuleTypes, ruleType) {
+			utility.LogError(fmt.Errorf("supported types: %v", qosRuleTypes),
+				fmt.Sprintf("invalid type %s", ruleType), true)
+		}
+		if flag := qosRuleRequiredFlags[ruleType]; !cmd.Flags().Changed(flag) {
+			console.Fatal("--%s is required for %s rule", flag, ruleType)
+		}
+		c := common.DefaultClient()
+
+		maxKbps, _ := cmd.Flags().GetInt("max-kbps")
+		maxBurstKbps, _ := cmd.Flags().GetInt("max-burst-kbps")
+		minKbps, _ := cmd.Flags().GetInt("min-kbps")
+		dscpMark, _ := cmd.Flags().GetInt("dscp-mark")
+		direction, _ := cmd.Flags().GetString("direction")
+
+		params := map[string]any{}
+		switch ruleType {
+		case QOS_RULE_BANDWIDTH_LIMIT:
+			params["max_kbps"] = maxKbps
+			if cmd.Flags().Changed("max-burst-kbps") {
+				params["max_burst_kbps"] = maxBurstKbps
+			}
+		case QOS_RULE_DSCP_MARKING:
+			params["dscp_mark"] = dscpMark
+		case QOS_RULE_MINIMUM_BANDWIDTH:
+			params["min_kbps"] = minKbps
+		}
+		if direction != "" && ruleType != QOS_RULE_DSCP_MARKING {
+			params["direction"] = direction
+		}
+		policy, err := c.NeutronV2().FindQosPolicy(args[0])
+		utility.LogIfError(err, true, "get qos policy %s failed", args[0])
+		rule, err := c.NeutronV2().CreateQosRule(policy.Id, strings.ReplaceAll(ruleType, "-", "_"), params)
+		utility.LogError(err, "create qos rule failed", true)
+		common.PrintQosPolicyRule(*rule)
+	},
+}
+var qosRuleDelete = &cobra.Command{
+	Use:   "delete <qos-policy> <rule> [rule ...]",
+	Short: "Delete qos rule(s)",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		policy, err := c.NeutronV2().FindQosPolicy(args[0])
+		utility.LogIfError(err, true, "get qos policy %s failed", args[0])
+		for _, id := range args[1:] {
+			// 删除规则需要指定规则类型
+			rule, ok := lo.Find(policy.Rules, func(r neutron.QosRule) bool { return r.Id == id })
+			if !ok {
+				utility.LogError(fmt.Errorf("rule %s not found in qos policy %s", id, policy.Name),
+					"delete qos rule failed", false)
+				continue
+			}
+			fmt.Printf("Reqeust to delete qos rule %s\n", id)
+			err := c.NeutronV2().DeleteQosRule(policy.Id, rule.Type, id)
+			utility.LogIfError(err, false, "delete qos rule %s failed", id)
+		}
+	},
+}
 
 func init() {
-	qosRule.AddCommand(qosRuleList)
+	qosRuleCreate.Flags().String("type", "", fmt.Sprintf("QoS rule type, %v", qosRuleTypes))
+	qosRuleCreate.Flags().Int("max-kbps", 0, "Maximum bandwidth in kbps (bandwidth-limit)")
+	qosRuleCreate.Flags().Int("max-burst-kbps", 0, "Maximum burst in kilobits, 0 means automatic (bandwidth-limit)")
+	qosRuleCreate.Flags().Int("min-kbps", 0, "Minimum guaranteed bandwidth in kbps (minimum-bandwidth)")
+	qosRuleCreate.Flags().Int("dscp-mark", 0, "DSCP mark (dscp-marking)")
+	qosRuleCreate.Flags().String("direction", "", "Traffic direction from the point of view of the port, ingress or egress")
+	qosRuleCreate.MarkFlagRequired("type")
+
+	qosRule.AddCommand(qosRuleList, qosRuleCreate, qosRuleDelete)
 	Qos.AddCommand(qosRule)
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
 	"github.com/BytemanD/skyman/utility"
 )
 
@@ -34,12 +35,13 @@ var routerList = &cobra.Command{
 var routerShow = &cobra.Command{
 	Use:   "show <router>",
 	Short: "Show router",
+	Long:  "Show router with gateway IPs, routes, interfaces and HA states on L3 agents",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		c := common.DefaultClient().NeutronV2()
-		router, err := c.FindRouter(args[0])
+		client := common.DefaultClient()
+		router, err := client.NeutronV2().FindRouter(args[0])
 		utility.LogError(err, "show router failed", true)
-		common.PrintRouter(*router)
+		printRouterDetail(getRouterDetail(client, router))
 	},
 }
 var routerDelete = &cobra.Command{
@@ -65,9 +67,12 @@ var routerDelete = &cobra.Command{
 var routerCreate = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create router",
-	Args:  cobra.ExactArgs(1),
+	Example: "router create router1 --ha\n" +
+		"router create router1 --external-gateway public --disable-snat",
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		c := common.DefaultClient().NeutronV2()
+		client := common.DefaultClient()
+		c := client.NeutronV2()
 		// name, _ := cmd.Flags().GetString("name")
 		disable, _ := cmd.Flags().GetBool("disable")
 		description, _ := cmd.Flags().GetString("description")
@@ -75,16 +80,105 @@ var routerCreate = &cobra.Command{
 			"name": args[0],
 		}
 		if disable {
-			params["enable"] = false
+			params["admin_state_up"] = false
 		}
 		if description != "" {
 			params["description"] = description
 		}
+		setRouterModeParams(cmd, params)
+		if gatewayInfo, changed := getGatewayInfo(cmd, client, nil); changed {
+			params["external_gateway_info"] = gatewayInfo
+		}
 		router, err := c.CreateRouter(params)
 		utility.LogError(err, "create router failed", true)
 		common.PrintRouter(*router)
 	},
 }
+var routerSet = &cobra.Command{
+	Use:   "set <router>",
+	Short: "Set router properties",
+	Long: "Set router properties.\n" +
+		"Changing --ha/--no-ha or --distributed/--centralized requires the router to be disabled.",
+	Example: "router set router1 --external-gateway public --fixed-ip subnet=public-subnet,ip=172.24.4.10\n" +
+		"router set router1 --route destination=10.10.0.0/16,gateway=192.168.1.254\n" +
+		"router set router1 --no-route",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := common.DefaultClient()
+		c := client.NeutronV2()
+
+		routeValues, _ := cmd.Flags().GetStringArray("route")
+		noRoute, _ := cmd.Flags().GetBool("no-route")
+		routes, err := parseRoutes(routeValues)
+		utility.LogError(err, "invalid route", true)
+
+		router, err := c.FindRouter(args[0])
+		utility.LogIfError(err, true, "get router %s failed", args[0])
+
+		params := map[string]any{}
+		if cmd.Flags().Changed("name") {
+			params["name"], _ = cmd.Flags().GetString("name")
+		}
+		if cmd.Flags().Changed("description") {
+			params["description"], _ = cmd.Flags().GetString("description")
+		}
+		for flag, value := range map[string]bool{"enable": true, "disable": false} {
+			if set, _ := cmd.Flags().GetBool(flag); set {
+				params["admin_state_up"] = value
+			}
+		}
+		setRouterModeParams(cmd, params)
+		if gatewayInfo, changed := getGatewayInfo(cmd, client, router); changed {
+			params["external_gateway_info"] = gatewayInfo
+		}
+		if noRoute {
+			params["routes"] = []neutron.Route{}
+		}
+		if len(params) == 0 && len(routes) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		if len(params) > 0 {
+			router, err = c.UpdateRouter(router.Id, params)
+			utility.LogError(err, "update router failed", true)
+		}
+		if len(routes) > 0 {
+			router, err = c.AddRouterExtraRoutes(router.Id, routes)
+			utility.LogError(err, "add routes failed", true)
+		}
+		common.PrintRouter(*router)
+	},
+}
+var routerUnset = &cobra.Command{
+	Use:   "unset <router>",
+	Short: "Unset router properties",
+	Example: "router unset router1 --external-gateway\n" +
+		"router unset router1 --route destination=10.10.0.0/16,gateway=192.168.1.254",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient().NeutronV2()
+
+		externalGateway, _ := cmd.Flags().GetBool("external-gateway")
+		routeValues, _ := cmd.Flags().GetStringArray("route")
+		routes, err := parseRoutes(routeValues)
+		utility.LogError(err, "invalid route", true)
+		if !externalGateway && len(routes) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		router, err := c.FindRouter(args[0])
+		utility.LogIfError(err, true, "get router %s failed", args[0])
+		if len(routes) > 0 {
+			router, err = c.RemoveRouterExtraRoutes(router.Id, routes)
+			utility.LogError(err, "remove routes failed", true)
+		}
+		if externalGateway {
+			router, err = c.UpdateRouter(router.Id, map[string]any{"external_gateway_info": map[string]any{}})
+			utility.LogError(err, "clear external gateway failed", true)
+		}
+		common.PrintRouter(*router)
+	},
+}
 
 var routerInterface = &cobra.Command{Use: "interface"}
 
@@ -179,9 +273,25 @@ func init() {
 
 	routerCreate.Flags().String("description", "", "Set router description")
 	routerCreate.Flags().Bool("disable", false, "Disable router")
+	registerRouterModeFlags(routerCreate)
+	registerGatewayFlags(routerCreate)
+
+	routerSet.Flags().String("name", "", "New name of the router")
+	routerSet.Flags().String("description", "", "New description of the router")
+	routerSet.Flags().Bool("enable", false, "Enable router")
+	routerSet.Flags().Bool("disable", false, "Disable router")
+	routerSet.MarkFlagsMutuallyExclusive("enable", "disable")
+	routerSet.Flags().StringArray("route", []string{}, routeUsage)
+	routerSet.Flags().Bool("no-route", false, "Clear all routes of the router")
+	routerSet.MarkFlagsMutuallyExclusive("route", "no-route")
+	registerRouterModeFlags(routerSet)
+	registerGatewayFlags(routerSet)
+
+	routerUnset.Flags().Bool("external-gateway", false, "Remove external gateway of the router")
+	routerUnset.Flags().StringArray("route", []string{}, routeUsage)
 
 	routerInterface.AddCommand(interfaceAdd, interfaceRemove, interfaceList)
 
-	Router.AddCommand(routerList, routerShow, routerDelete, routerCreate,
+	Router.AddCommand(routerList, routerShow, routerDelete, routerCreate, routerSet, routerUnset,
 		routerInterface)
 }
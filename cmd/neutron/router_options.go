@@ -0,0 +1,201 @@
+package neutron
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const routeUsage = "Static route, format: destination=<cidr>,gateway=<ip-address>"
+
+var routerInterfaceOwners = []string{
+	"network:router_interface", "network:router_interface_distributed", "network:ha_router_replicated_interface",
+}
+
+func parseRoutes(values []string) ([]neutron.Route, error) {
+	routes := []neutron.Route{}
+	for _, value := range values {
+		kv, err := parseKeyValues(value, map[string][]string{
+			"destination": {"destination", "dest"}, "gateway": {"gateway", "nexthop"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if kv["destination"] == "" || kv["gateway"] == "" {
+			return nil, fmt.Errorf("destination and gateway are required in '%s'", value)
+		}
+		routes = append(routes, neutron.Route{Destination: kv["destination"], Nexthop: kv["gateway"]})
+	}
+	return routes, nil
+}
+
+func registerRouterModeFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("ha", false, "Highly available router")
+	cmd.Flags().Bool("no-ha", false, "Legacy router")
+	cmd.Flags().Bool("distributed", false, "Distributed router")
+	cmd.Flags().Bool("centralized", false, "Centralized router")
+	cmd.MarkFlagsMutuallyExclusive("ha", "no-ha")
+	cmd.MarkFlagsMutuallyExclusive("distributed", "centralized")
+}
+func setRouterModeParams(cmd *cobra.Command, params map[string]any) {
+	for flag, value := range map[string]bool{"ha": true, "no-ha": false} {
+		if set, _ := cmd.Flags().GetBool(flag); set {
+			params["ha"] = value
+		}
+	}
+	for flag, value := range map[string]bool{"distributed": true, "centralized": false} {
+		if set, _ := cmd.Flags().GetBool(flag); set {
+			params["distributed"] = value
+		}
+	}
+}
+
+func registerGatewayFlags(cmd *cobra.Command) {
+	cmd.Flags().String("external-gateway", "", "External network used as router's gateway (name or ID)")
+	cmd.Flags().StringArray("fixed-ip", []string{},
+		"Fixed IP of the external gateway, format: subnet=<subnet>,ip-address=<ip-address>")
+	cmd.Flags().Bool("enable-snat", false, "Enable source NAT on external gateway")
+	cmd.Flags().Bool("disable-snat", false, "Disable source NAT on external gateway")
+	cmd.MarkFlagsMutuallyExclusive("enable-snat", "disable-snat")
+}
+
+// 返回 external_gateway_info 参数, 未指定外部网络时使用路由器当前的外部网络
+func getGatewayInfo(cmd *cobra.Command, client *openstack.Openstack, router *neutron.Router) (map[string]any, bool) {
+	externalGateway, _ := cmd.Flags().GetString("external-gateway")
+	fixedIpValues, _ := cmd.Flags().GetStringArray("fixed-ip")
+	enableSnat, _ := cmd.Flags().GetBool("enable-snat")
+	disableSnat, _ := cmd.Flags().GetBool("disable-snat")
+	if externalGateway == "" && len(fixedIpValues) == 0 && !enableSnat && !disableSnat {
+		return nil, false
+	}
+	gatewayInfo := map[string]any{}
+	switch {
+	case externalGateway != "":
+		network, err := client.NeutronV2().FindNetwork(externalGateway)
+		utility.LogIfError(err, true, "get network %s failed", externalGateway)
+		gatewayInfo["network_id"] = network.Id
+	case router != nil && router.ExternalGatewayInfo != nil:
+		gatewayInfo["network_id"] = router.ExternalGatewayInfo.NetworkId
+	default:
+		console.Error("--external-gateway is required")
+		os.Exit(1)
+	}
+	if len(fixedIpValues) > 0 {
+		fixedIps, err := parseFixedIps(client, fixedIpValues)
+		utility.LogError(err, "invalid fixed ip", true)
+		gatewayInfo["external_fixed_ips"] = fixedIps
+	}
+	if enableSnat || disableSnat {
+		gatewayInfo["enable_snat"] = enableSnat
+	}
+	return gatewayInfo, true
+}
+
+type routerInterfaceInfo struct {
+	PortId    string `json:"port_id"`
+	IpAddress string `json:"ip_address"`
+	SubnetId  string `json:"subnet_id"`
+	Subnet    string `json:"subnet"`
+	Cidr      string `json:"cidr"`
+}
+
+func (i routerInterfaceInfo) String() string {
+	return fmt.Sprintf("%s (%s %s)", i.IpAddress, lo.CoalesceOrEmpty(i.Subnet, i.SubnetId), i.Cidr)
+}
+
+type routerDetail struct {
+	neutron.Router
+	Interfaces []routerInterfaceInfo `json:"interfaces"`
+	L3Agents   []neutron.Agent       `json:"l3_agents,omitempty"`
+}
+
+func getRouterDetail(client *openstack.Openstack, router *neutron.Router) routerDetail {
+	detail := routerDetail{Router: *router, Interfaces: []routerInterfaceInfo{}}
+
+	ports, err := client.NeutronV2().ListPort(url.Values{"device_id": []string{router.Id}})
+	utility.LogError(err, "list router ports failed", true)
+	subnets := map[string]*neutron.Subnet{}
+	for _, port := range ports {
+		if !lo.Contains(routerInterfaceOwners, port.DeviceOwner) {
+			continue
+		}
+		for _, fixedIp := range port.FixedIps {
+			if _, ok := subnets[fixedIp.SubnetId]; !ok {
+				subnet, err := client.NeutronV2().GetSubnet(fixedIp.SubnetId)
+				if err != nil {
+					console.Warn("get subnet %s failed: %s", fixedIp.SubnetId, err)
+				}
+				subnets[fixedIp.SubnetId] = subnet
+			}
+			info := routerInterfaceInfo{PortId: port.Id, IpAddress: fixedIp.IpAddress, SubnetId: fixedIp.SubnetId}
+			if subnet := subnets[fixedIp.SubnetId]; subnet != nil {
+				info.Subnet, info.Cidr = subnet.Name, subnet.Cidr
+			}
+			detail.Interfaces = append(detail.Interfaces, info)
+		}
+	}
+	// 查询 L3 agent 需要管理员权限
+	if agents, err := client.NeutronV2().ListRouterAgents(router.Id); err != nil {
+		console.Warn("list l3 agents of router failed: %s", err)
+	} else {
+		detail.L3Agents = agents
+	}
+	return detail
+}
+
+func printRouterDetail(detail routerDetail) {
+	common.PrintItem(
+		[]datatable.Field[routerDetail]{
+			{Name: "Id"}, {Name: "Name"}, {Name: "Description"},
+			{Name: "Status"}, {Name: "AdminStateUp"},
+			{Name: "Distributed"}, {Name: "HA", Text: "HA"},
+			{Name: "ExternalNetwork", RenderFunc: func(item routerDetail) any {
+				if item.ExternalGatewayInfo == nil {
+					return ""
+				}
+				return item.ExternalGatewayInfo.NetworkId
+			}},
+			{Name: "EnableSnat", RenderFunc: func(item routerDetail) any {
+				if item.ExternalGatewayInfo == nil || item.ExternalGatewayInfo.EnableSnat == nil {
+					return ""
+				}
+				return *item.ExternalGatewayInfo.EnableSnat
+			}},
+			{Name: "GatewayIps", RenderFunc: func(item routerDetail) any {
+				return strings.Join(item.GatewayIps(), "\n")
+			}},
+			{Name: "Routes", RenderFunc: func(item routerDetail) any {
+				return strings.Join(item.RouteStrings(), "\n")
+			}},
+			{Name: "Interfaces", RenderFunc: func(item routerDetail) any {
+				return strings.Join(lo.Map(item.Interfaces, func(i routerInterfaceInfo, _ int) string {
+					return i.String()
+				}), "\n")
+			}},
+			{Name: "L3Agents", Text: "L3 Agents", RenderFunc: func(item routerDetail) any {
+				return strings.Join(lo.Map(item.L3Agents, func(a neutron.Agent, _ int) string {
+					if a.HaState == "" {
+						return fmt.Sprintf("%s %s", a.Host, a.AliveEmoji())
+					}
+					return fmt.Sprintf("%s %s %s", a.Host, a.AliveEmoji(), a.HaState)
+				}), "\n")
+			}},
+			{Name: "AvailabilityZones"},
+			{Name: "ProjectId"},
+			{Name: "CreatedAt"}, {Name: "UpdatedAt"},
+		},
+		[]datatable.Field[routerDetail]{},
+		detail, common.TableOptions{},
+	)
+}
@@ -1,8 +1,10 @@
 package neutron
 
 import (
+	"fmt"
 	"net/url"
 
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
 	"github.com/BytemanD/skyman/utility"
 	"github.com/spf13/cobra"
@@ -49,11 +51,85 @@ var sgShow = &cobra.Command{
 	},
 }
 
+var sgCreate = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create security group",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		description, _ := cmd.Flags().GetString("description")
+		projectIdOrName, _ := cmd.Flags().GetString("project")
+		params := map[string]any{"name": args[0]}
+		if description != "" {
+			params["description"] = description
+		}
+		if projectIdOrName != "" {
+			project, err := c.KeystoneV3().FindProject(projectIdOrName)
+			utility.LogError(err, "get project failed", true)
+			params["project_id"] = project.Id
+		}
+		sg, err := c.NeutronV2().CreateSecurityGroup(params)
+		utility.LogError(err, "create security group failed", true)
+		common.PrintSecurityGroup(*sg)
+	},
+}
+var sgSet = &cobra.Command{
+	Use:   "set <id or name>",
+	Short: "Set security group properties",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		params := map[string]any{}
+		if cmd.Flags().Changed("name") {
+			params["name"], _ = cmd.Flags().GetString("name")
+		}
+		if cmd.Flags().Changed("description") {
+			params["description"], _ = cmd.Flags().GetString("description")
+		}
+		if len(params) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		sg, err := c.NeutronV2().FindSecurityGroup(args[0])
+		utility.LogError(err, "get security group failed", true)
+		sg, err = c.NeutronV2().UpdateSecurityGroup(sg.Id, params)
+		utility.LogError(err, "update security group failed", true)
+		common.PrintSecurityGroup(*sg)
+	},
+}
+var sgDelete = &cobra.Command{
+	Use:   "delete <id or name> [id or name ...]",
+	Short: "Delete security group(s)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		for _, idOrName := range args {
+			sg, err := c.NeutronV2().FindSecurityGroup(idOrName)
+			if err != nil {
+				utility.LogError(err, fmt.Sprintf("get security group %s failed", idOrName), false)
+				continue
+			}
+			fmt.Printf("Reqeust to delete security group %s\n", idOrName)
+			err = c.NeutronV2().DeleteSecurityGroup(sg.Id)
+			utility.LogIfError(err, false, "delete security group %s failed", idOrName)
+		}
+	},
+}
+
 func init() {
 	sgList.Flags().BoolP("long", "l", false, "List additional fields in output")
 	sgList.Flags().StringP("project", "", "", "List according to the project")
 
-	group.AddCommand(sgList, sgShow)
+	sgCreate.Flags().String("description", "", "Security group description")
+	sgCreate.Flags().String("project", "", "Owner's project (name or ID)")
+
+	sgSet.Flags().String("name", "", "New security group name")
+	sgSet.Flags().String("description", "", "New security group description")
+
+	group.AddCommand(sgList, sgShow, sgCreate, sgSet, sgDelete)
 	Security.AddCommand(group)
-	SG.AddCommand(sgList, sgShow)
+	SG.AddCommand(sgList, sgShow, sgCreate, sgSet, sgDelete)
 }
@@ -1,9 +1,14 @@
 package neutron
 
 import (
+	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
 
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
 	"github.com/BytemanD/skyman/utility"
 	"github.com/spf13/cobra"
 )
@@ -44,11 +49,133 @@ var sgRuleShow = &cobra.Command{
 	},
 }
 
+// 解析端口范围, 格式: <port> 或 <min>:<max>
+func parsePortRange(portRange string) (int, int, error) {
+	values := strings.SplitN(portRange, ":", 2)
+	ports := []int{}
+	for _, value := range values {
+		port, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || port < 0 || port > 65535 {
+			return 0, 0, fmt.Errorf("invalid port range: %s", portRange)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 1 {
+		return ports[0], ports[0], nil
+	}
+	if ports[0] > ports[1] {
+		return 0, 0, fmt.Errorf("invalid port range: %s, min is greater than max", portRange)
+	}
+	return ports[0], ports[1], nil
+}
+
+func sgRuleParams(rule neutron.SecurityGroupRule) map[string]any {
+	params := map[string]any{
+		"security_group_id": rule.SecurityGroupId,
+		"direction":         rule.Direction,
+		"ethertype":         rule.Ethertype,
+	}
+	if rule.Protocol != "" {
+		params["protocol"] = rule.Protocol
+	}
+	if rule.PortRangeMin != nil {
+		params["port_range_min"] = *rule.PortRangeMin
+	}
+	if rule.PortRangeMax != nil {
+		params["port_range_max"] = *rule.PortRangeMax
+	}
+	if rule.RemoteIpPrefix != "" {
+		params["remote_ip_prefix"] = rule.RemoteIpPrefix
+	}
+	if rule.RemoteGroupId != "" {
+		params["remote_group_id"] = rule.RemoteGroupId
+	}
+	if rule.Description != "" {
+		params["description"] = rule.Description
+	}
+	return params
+}
+
+var sgRuleCreate = &cobra.Command{
+	Use:   "create <security group>",
+	Short: "Create security group rule",
+	Example: "security group rule create default --protocol tcp --dst-port 22\n" +
+		"security group rule create default --protocol tcp --dst-port 8000:8080 --remote-ip 10.0.0.0/8\n" +
+		"security group rule create default --egress --ethertype IPv6 --remote-group default",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		egress, _ := cmd.Flags().GetBool("egress")
+		ethertype, _ := cmd.Flags().GetString("ethertype")
+		protocol, _ := cmd.Flags().GetString("protocol")
+		dstPort, _ := cmd.Flags().GetString("dst-port")
+		remoteIp, _ := cmd.Flags().GetString("remote-ip")
+		remoteGroup, _ := cmd.Flags().GetString("remote-group")
+		description, _ := cmd.Flags().GetString("description")
+
+		sg, err := c.NeutronV2().FindSecurityGroup(args[0])
+		utility.LogError(err, "get security group failed", true)
+
+		rule := neutron.SecurityGroupRule{
+			SecurityGroupId: sg.Id, Direction: "ingress", Ethertype: ethertype,
+			Protocol: strings.ToLower(protocol), RemoteIpPrefix: remoteIp,
+		}
+		rule.Description = description
+		if egress {
+			rule.Direction = "egress"
+		}
+		if dstPort != "" {
+			portMin, portMax, err := parsePortRange(dstPort)
+			utility.LogError(err, "parse port range failed", true)
+			rule.PortRangeMin, rule.PortRangeMax = &portMin, &portMax
+		}
+		if remoteGroup != "" {
+			remoteSg, err := c.NeutronV2().FindSecurityGroup(remoteGroup)
+			utility.LogError(err, "get remote security group failed", true)
+			rule.RemoteGroupId = remoteSg.Id
+		}
+		for _, existing := range sg.Rules {
+			if existing.Equal(rule) {
+				console.Warn("rule already exists in security group %s: %s", sg.Name, existing.Id)
+				common.PrintSecurityGroupRule(existing)
+				return
+			}
+		}
+		sgRule, err := c.NeutronV2().CreateSecurityGroupRule(sgRuleParams(rule))
+		utility.LogError(err, "create security group rule failed", true)
+		common.PrintSecurityGroupRule(*sgRule)
+	},
+}
+var sgRuleDelete = &cobra.Command{
+	Use:   "delete <id> [id ...]",
+	Short: "Delete security group rule(s)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		for _, id := range args {
+			fmt.Printf("Reqeust to delete security group rule %s\n", id)
+			err := c.NeutronV2().DeleteSecurityGroupRule(id)
+			utility.LogIfError(err, false, "delete security group rule %s failed", id)
+		}
+	},
+}
+
 func init() {
 	sgRuleList.Flags().BoolP("long", "l", false, "List additional fields in output")
 	sgRuleList.Flags().StringP("security-group", "", "", "List according to the project")
 
-	rule.AddCommand(sgRuleList, sgRuleShow)
+	sgRuleCreate.Flags().Bool("egress", false, "Rule applies to outgoing network traffic (default: ingress)")
+	sgRuleCreate.Flags().String("ethertype", "IPv4", "Ethertype of network traffic, IPv4 or IPv6")
+	sgRuleCreate.Flags().String("protocol", "", "IP protocol, e.g. tcp, udp, icmp or IP protocol number")
+	sgRuleCreate.Flags().String("dst-port", "", "Destination port, may be a single port or a range: 8000:8080")
+	sgRuleCreate.Flags().String("remote-ip", "", "Remote IP address block (CIDR notation)")
+	sgRuleCreate.Flags().String("remote-group", "", "Remote security group (name or ID)")
+	sgRuleCreate.Flags().String("description", "", "Security group rule description")
+	sgRuleCreate.MarkFlagsMutuallyExclusive("remote-ip", "remote-group")
+
+	rule.AddCommand(sgRuleList, sgRuleShow, sgRuleCreate, sgRuleDelete)
 
 	group.AddCommand(rule)
 	SG.AddCommand(rule)
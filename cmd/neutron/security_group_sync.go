@@ -0,0 +1,426 @@
+package neutron
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const (
+	SG_PLAN_CREATE = "create"
+	SG_PLAN_UPDATE = "update"
+	SG_PLAN_ADD    = "add"
+	SG_PLAN_REMOVE = "remove"
+
+	// 新建的安全组在应用前还没有 ID, 远端安全组先用占位符表示
+	sgPlaceholderPrefix = "new:"
+)
+
+type SgRuleSpec struct {
+	Direction      string `yaml:"direction"`
+	Ethertype      string `yaml:"ethertype"`
+	Protocol       string `yaml:"protocol,omitempty"`
+	PortRange      string `yaml:"port_range,omitempty"`
+	IcmpType       *int   `yaml:"icmp_type,omitempty"`
+	IcmpCode       *int   `yaml:"icmp_code,omitempty"`
+	RemoteIpPrefix string `yaml:"remote_ip_prefix,omitempty"`
+	RemoteGroup    string `yaml:"remote_group,omitempty"`
+	Description    string `yaml:"description,omitempty"`
+}
+
+type SgSpec struct {
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description,omitempty"`
+	Rules       []SgRuleSpec `yaml:"rules"`
+}
+
+type SgPolicy struct {
+	SecurityGroups []SgSpec `yaml:"security_groups"`
+}
+
+type sgPlanItem struct {
+	Action string
+	Group  string
+	Detail string
+	rule   neutron.SecurityGroupRule
+}
+
+func (spec SgRuleSpec) String() string {
+	values := []string{spec.Direction, spec.Ethertype, lo.CoalesceOrEmpty(spec.Protocol, "any")}
+	if spec.PortRange != "" {
+		values = append(values, "port "+spec.PortRange)
+	}
+	if spec.IcmpType != nil {
+		values = append(values, fmt.Sprintf("type %d", *spec.IcmpType))
+	}
+	if spec.IcmpCode != nil {
+		values = append(values, fmt.Sprintf("code %d", *spec.IcmpCode))
+	}
+	switch {
+	case spec.RemoteGroup != "":
+		values = append(values, "remote group "+spec.RemoteGroup)
+	case spec.RemoteIpPrefix != "":
+		values = append(values, "remote "+spec.RemoteIpPrefix)
+	}
+	return strings.Join(values, " ")
+}
+
+// groupIds 为安全组名称到 ID 的映射, 用于解析远端安全组
+func (spec SgRuleSpec) toRule(groupIds map[string]string) (neutron.SecurityGroupRule, error) {
+	rule := neutron.SecurityGroupRule{
+		Direction:      lo.CoalesceOrEmpty(strings.ToLower(spec.Direction), "ingress"),
+		Ethertype:      lo.CoalesceOrEmpty(spec.Ethertype, "IPv4"),
+		Protocol:       strings.ToLower(spec.Protocol),
+		RemoteIpPrefix: spec.RemoteIpPrefix,
+	}
+	rule.Description = spec.Description
+	if rule.IsIcmp() {
+		if spec.PortRange != "" {
+			return rule, fmt.Errorf("port_range is invalid for %s, use icmp_type and icmp_code", rule.Protocol)
+		}
+		if spec.IcmpCode != nil && spec.IcmpType == nil {
+			return rule, fmt.Errorf("icmp_type is required when icmp_code is specified")
+		}
+		for _, value := range []*int{spec.IcmpType, spec.IcmpCode} {
+			if value != nil && (*value < 0 || *value > 255) {
+				return rule, fmt.Errorf("invalid icmp type or code: %d", *value)
+			}
+		}
+		// code 为空表示任意 code
+		rule.PortRangeMin, rule.PortRangeMax = spec.IcmpType, spec.IcmpCode
+	} else if spec.IcmpType != nil || spec.IcmpCode != nil {
+		return rule, fmt.Errorf("icmp_type and icmp_code are only valid for icmp rules")
+	} else if spec.PortRange != "" {
+		portMin, portMax, err := parsePortRange(spec.PortRange)
+		if err != nil {
+			return rule, err
+		}
+		rule.PortRangeMin, rule.PortRangeMax = &portMin, &portMax
+	}
+	if spec.RemoteGroup != "" {
+		groupId, ok := groupIds[spec.RemoteGroup]
+		if !ok {
+			return rule, fmt.Errorf("remote group %s not found", spec.RemoteGroup)
+		}
+		rule.RemoteGroupId = groupId
+	}
+	return rule, nil
+}
+
+// groupNames 为安全组 ID 到名称的映射, 远端安全组导出为名称, 以便在不同项目或区域之间比较
+func sgRuleToSpec(rule neutron.SecurityGroupRule, groupNames map[string]string) SgRuleSpec {
+	spec := SgRuleSpec{
+		Direction: rule.Direction, Ethertype: rule.Ethertype, Protocol: rule.Protocol,
+		RemoteIpPrefix: rule.RemoteIpPrefix, Description: rule.Description,
+	}
+	// ICMP 规则的 port_range_min/max 分别是 type 和 code
+	if rule.IsIcmp() {
+		spec.IcmpType, spec.IcmpCode = rule.PortRangeMin, rule.PortRangeMax
+	} else {
+		spec.PortRange = rule.PortRange()
+	}
+	if rule.RemoteGroupId != "" {
+		spec.RemoteGroup = lo.CoalesceOrEmpty(groupNames[rule.RemoteGroupId], rule.RemoteGroupId)
+	}
+	return spec
+}
+
+func loadSgPolicy(file string) (*SgPolicy, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	policy := SgPolicy{}
+	if err := yaml.Unmarshal(content, &policy); err != nil {
+		return nil, fmt.Errorf("parse %s failed: %w", file, err)
+	}
+	names := map[string]bool{}
+	for _, sg := range policy.SecurityGroups {
+		if sg.Name == "" {
+			return nil, fmt.Errorf("security group name is required")
+		}
+		if names[sg.Name] {
+			return nil, fmt.Errorf("security group %s is declared more than once", sg.Name)
+		}
+		names[sg.Name] = true
+	}
+	return &policy, nil
+}
+
+// 只有文件中声明的安全组不允许重名
+func listSgByName(c *openstack.Openstack, projectId string, policy SgPolicy) (map[string]neutron.SecurityGroup, error) {
+	sgs, err := c.NeutronV2().ListSecurityGroup(url.Values{"project_id": []string{projectId}})
+	if err != nil {
+		return nil, err
+	}
+	declared := lo.Map(policy.SecurityGroups, func(spec SgSpec, _ int) string { return spec.Name })
+	sgMap := map[string]neutron.SecurityGroup{}
+	for _, sg := range sgs {
+		if _, ok := sgMap[sg.Name]; ok && lo.Contains(declared, sg.Name) {
+			return nil, fmt.Errorf("found multiple security groups named %s", sg.Name)
+		}
+		sgMap[sg.Name] = sg
+	}
+	return sgMap, nil
+}
+
+// 对比安全组规则, 返回需要添加和删除的规则
+func diffSgRules(existing, desired []neutron.SecurityGroupRule) ([]neutron.SecurityGroupRule, []neutron.SecurityGroupRule) {
+	adds := lo.Filter(desired, func(rule neutron.SecurityGroupRule, _ int) bool {
+		return !lo.ContainsBy(existing, rule.Equal)
+	})
+	removes := lo.Filter(existing, func(rule neutron.SecurityGroupRule, _ int) bool {
+		return !lo.ContainsBy(desired, rule.Equal)
+	})
+	return adds, removes
+}
+
+// neutron 创建安全组时默认添加的出方向规则
+func defaultSgRules() []neutron.SecurityGroupRule {
+	return []neutron.SecurityGroupRule{
+		{Direction: "egress", Ethertype: "IPv4"},
+		{Direction: "egress", Ethertype: "IPv6"},
+	}
+}
+
+func desiredSgRules(spec SgSpec, groupIds map[string]string) ([]neutron.SecurityGroupRule, error) {
+	rules := []neutron.SecurityGroupRule{}
+	for _, ruleSpec := range spec.Rules {
+		rule, err := ruleSpec.toRule(groupIds)
+		if err != nil {
+			return nil, fmt.Errorf("security group %s: %w", spec.Name, err)
+		}
+		if !lo.ContainsBy(rules, rule.Equal) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func planSgPolicy(policy SgPolicy, sgMap map[string]neutron.SecurityGroup) ([]sgPlanItem, error) {
+	groupIds := lo.MapValues(sgMap, func(sg neutron.SecurityGroup, _ string) string { return sg.Id })
+	groupNames := lo.Invert(groupIds)
+	for _, spec := range policy.SecurityGroups {
+		if _, ok := groupIds[spec.Name]; !ok {
+			groupIds[spec.Name] = sgPlaceholderPrefix + spec.Name
+			groupNames[sgPlaceholderPrefix+spec.Name] = spec.Name
+		}
+	}
+	plan := []sgPlanItem{}
+	for _, spec := range policy.SecurityGroups {
+		existingRules := defaultSgRules()
+		if sg, ok := sgMap[spec.Name]; ok {
+			existingRules = sg.Rules
+			if sg.Description != spec.Description {
+				plan = append(plan, sgPlanItem{
+					Action: SG_PLAN_UPDATE, Group: spec.Name,
+					Detail: fmt.Sprintf("description: %q -> %q", sg.Description, spec.Description),
+				})
+			}
+		} else {
+			plan = append(plan, sgPlanItem{Action: SG_PLAN_CREATE, Group: spec.Name, Detail: spec.Description})
+		}
+		desired, err := desiredSgRules(spec, groupIds)
+		if err != nil {
+			return nil, err
+		}
+		adds, removes := diffSgRules(existingRules, desired)
+		for _, rule := range removes {
+			plan = append(plan, sgPlanItem{
+				Action: SG_PLAN_REMOVE, Group: spec.Name, rule: rule,
+				Detail: sgRuleToSpec(rule, groupNames).String(),
+			})
+		}
+		for _, rule := range adds {
+			plan = append(plan, sgPlanItem{
+				Action: SG_PLAN_ADD, Group: spec.Name, rule: rule,
+				Detail: sgRuleToSpec(rule, groupNames).String(),
+			})
+		}
+	}
+	return plan, nil
+}
+
+// 先创建和更新安全组, 再根据最新的规则重新对比并同步
+func applySgPolicy(c *openstack.Openstack, policy SgPolicy, sgMap map[string]neutron.SecurityGroup,
+	projectId string) error {
+	groupIds := lo.MapValues(sgMap, func(sg neutron.SecurityGroup, _ string) string { return sg.Id })
+	for _, spec := range policy.SecurityGroups {
+		sg, ok := sgMap[spec.Name]
+		switch {
+		case !ok:
+			params := map[string]any{"name": spec.Name, "description": spec.Description}
+			if projectId != "" {
+				params["project_id"] = projectId
+			}
+			created, err := c.NeutronV2().CreateSecurityGroup(params)
+			if err != nil {
+				return fmt.Errorf("create security group %s failed: %w", spec.Name, err)
+			}
+			console.Info("created security group %s (%s)", spec.Name, created.Id)
+			groupIds[spec.Name] = created.Id
+		case sg.Description != spec.Description:
+			_, err := c.NeutronV2().UpdateSecurityGroup(sg.Id, map[string]any{"description": spec.Description})
+			if err != nil {
+				return fmt.Errorf("update security group %s failed: %w", spec.Name, err)
+			}
+			console.Info("updated security group %s", spec.Name)
+		}
+	}
+	for _, spec := range policy.SecurityGroups {
+		sg, err := c.NeutronV2().GetSecurityGroup(groupIds[spec.Name])
+		if err != nil {
+			return fmt.Errorf("get security group %s failed: %w", spec.Name, err)
+		}
+		desired, err := desiredSgRules(spec, groupIds)
+		if err != nil {
+			return err
+		}
+		adds, removes := diffSgRules(sg.Rules, desired)
+		for _, rule := range removes {
+			if err := c.NeutronV2().DeleteSecurityGroupRule(rule.Id); err != nil {
+				return fmt.Errorf("delete rule %s of %s failed: %w", rule.Id, spec.Name, err)
+			}
+			console.Info("removed rule %s from %s", rule.Id, spec.Name)
+		}
+		for _, rule := range adds {
+			rule.SecurityGroupId = sg.Id
+			created, err := c.NeutronV2().CreateSecurityGroupRule(sgRuleParams(rule))
+			if err != nil {
+				return fmt.Errorf("add rule to %s failed: %w", spec.Name, err)
+			}
+			console.Info("added rule %s to %s", created.Id, spec.Name)
+		}
+	}
+	return nil
+}
+
+// 未指定项目时使用当前 token 的项目
+func getProjectId(c *openstack.Openstack, projectIdOrName string) string {
+	if projectIdOrName == "" {
+		projectId, err := c.ProjectId()
+		utility.LogError(err, "get project id failed", true)
+		return projectId
+	}
+	project, err := c.KeystoneV3().FindProject(projectIdOrName)
+	utility.LogError(err, "get project failed", true)
+	return project.Id
+}
+
+var sgApply = &cobra.Command{
+	Use:   "apply <file>",
+	Short: "Sync security groups and rules from yaml file",
+	Long: "Compare security groups and rules declared in yaml file with the cloud, print the plan,\n" +
+		"and apply it with --yes. Rules not declared in file will be removed.\n" +
+		"The format of file is the same as the output of 'security group export'.",
+	Example: "security group apply policy.yaml\n" +
+		"security group apply policy.yaml --project demo --yes",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		yes, _ := cmd.Flags().GetBool("yes")
+		projectIdOrName, _ := cmd.Flags().GetString("project")
+
+		policy, err := loadSgPolicy(args[0])
+		utility.LogError(err, "load security group policy failed", true)
+
+		c := common.DefaultClient()
+		projectId := getProjectId(c, projectIdOrName)
+		sgMap, err := listSgByName(c, projectId, *policy)
+		utility.LogError(err, "list security groups failed", true)
+
+		plan, err := planSgPolicy(*policy, sgMap)
+		utility.LogError(err, "plan failed", true)
+		if len(plan) == 0 {
+			console.Success("security groups are up to date")
+			return
+		}
+		common.PrintItems(
+			[]datatable.Column[sgPlanItem]{
+				{Name: "Action", RenderFunc: func(item sgPlanItem) any {
+					switch item.Action {
+					case SG_PLAN_CREATE, SG_PLAN_ADD:
+						return "+ " + item.Action
+					case SG_PLAN_REMOVE:
+						return "- " + item.Action
+					default:
+						return "~ " + item.Action
+					}
+				}},
+				{Name: "Group"}, {Name: "Detail"},
+			},
+			nil, plan, common.TableOptions{},
+		)
+		if !yes {
+			console.Info("%d change(s) planned, use --yes to apply", len(plan))
+			return
+		}
+		err = applySgPolicy(c, *policy, sgMap, projectId)
+		utility.LogError(err, "apply security group policy failed", true)
+		console.Success("applied %d change(s)", len(plan))
+	},
+}
+
+var sgExport = &cobra.Command{
+	Use:   "export <id or name> [id or name ...]",
+	Short: "Export security groups and rules as yaml",
+	Example: "security group export default web\n" +
+		"security group export web --output web.yaml",
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		c := common.DefaultClient()
+		allSgs, err := c.NeutronV2().ListSecurityGroup(nil)
+		utility.LogError(err, "list security groups failed", true)
+		groupNames := lo.SliceToMap(allSgs, func(sg neutron.SecurityGroup) (string, string) {
+			return sg.Id, sg.Name
+		})
+
+		policy := SgPolicy{}
+		for _, idOrName := range args {
+			sg, err := c.NeutronV2().FindSecurityGroup(idOrName)
+			utility.LogError(err, fmt.Sprintf("get security group %s failed", idOrName), true)
+			groupNames[sg.Id] = sg.Name
+			spec := SgSpec{Name: sg.Name, Description: sg.Description}
+			for _, rule := range sg.Rules {
+				spec.Rules = append(spec.Rules, sgRuleToSpec(rule, groupNames))
+			}
+			slices.SortFunc(spec.Rules, func(a, b SgRuleSpec) int {
+				return strings.Compare(a.String(), b.String())
+			})
+			policy.SecurityGroups = append(policy.SecurityGroups, spec)
+		}
+		content, err := yaml.Marshal(policy)
+		utility.LogError(err, "marshal yaml failed", true)
+		if output == "" {
+			fmt.Print(string(content))
+			return
+		}
+		err = os.WriteFile(output, content, 0644)
+		utility.LogError(err, "write file failed", true)
+		console.Info("exported %d security group(s) to %s", len(policy.SecurityGroups), output)
+	},
+}
+
+func init() {
+	sgApply.Flags().BoolP("yes", "y", false, "Apply the plan")
+	sgApply.Flags().String("project", "", "Owner's project (name or ID) of security groups, default: current project")
+
+	sgExport.Flags().StringP("output", "o", "", "Write yaml to file")
+
+	group.AddCommand(sgApply, sgExport)
+	SG.AddCommand(sgApply, sgExport)
+}
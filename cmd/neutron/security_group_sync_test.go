@@ -0,0 +1,69 @@
+package neutron
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+
+	"github.com/BytemanD/skyman/openstack/model/neutron"
+)
+
+func TestSgRuleExportApplyRoundTrip(t *testing.T) {
+	rules := []neutron.SecurityGroupRule{
+		{Direction: "ingress", Ethertype: "IPv4", Protocol: "icmp", PortRangeMin: lo.ToPtr(8), PortRangeMax: lo.ToPtr(0)},
+		{Direction: "ingress", Ethertype: "IPv4", Protocol: "icmp", PortRangeMin: lo.ToPtr(3), PortRangeMax: lo.ToPtr(4)},
+		// code 为空 (任意 code) 与 code 0 不同
+		{Direction: "ingress", Ethertype: "IPv4", Protocol: "icmp", PortRangeMin: lo.ToPtr(3)},
+		{Direction: "ingress", Ethertype: "IPv4", Protocol: "icmp", PortRangeMin: lo.ToPtr(0)},
+		{Direction: "ingress", Ethertype: "IPv6", Protocol: "ipv6-icmp", PortRangeMin: lo.ToPtr(128)},
+		{Direction: "ingress", Ethertype: "IPv4", Protocol: "icmp"},
+		{Direction: "ingress", Ethertype: "IPv4", Protocol: "tcp", PortRangeMin: lo.ToPtr(22), PortRangeMax: lo.ToPtr(22)},
+		{Direction: "ingress", Ethertype: "IPv4", Protocol: "udp", PortRangeMin: lo.ToPtr(8000), PortRangeMax: lo.ToPtr(8080),
+			RemoteIpPrefix: "10.0.0.0/8"},
+		{Direction: "egress", Ethertype: "IPv4", RemoteGroupId: "sg-web-id"},
+	}
+	groupNames := map[string]string{"sg-web-id": "web"}
+	groupIds := map[string]string{"web": "sg-web-id"}
+
+	spec := SgSpec{Name: "test"}
+	for _, rule := range rules {
+		spec.Rules = append(spec.Rules, sgRuleToSpec(rule, groupNames))
+	}
+	content, err := yaml.Marshal(SgPolicy{SecurityGroups: []SgSpec{spec}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := SgPolicy{}
+	if err := yaml.Unmarshal(content, &policy); err != nil {
+		t.Fatal(err)
+	}
+	desired, err := desiredSgRules(policy.SecurityGroups[0], groupIds)
+	if err != nil {
+		t.Fatalf("apply exported rules failed: %s\n%s", err, content)
+	}
+	for i, rule := range desired {
+		params := sgRuleParams(rule)
+		_, hasMax := params["port_range_max"]
+		if hasMax != (rules[i].PortRangeMax != nil) {
+			t.Errorf("rule %s: unexpected port_range_max in params %v", rules[i], params)
+		}
+	}
+	adds, removes := diffSgRules(rules, desired)
+	if len(adds) != 0 || len(removes) != 0 {
+		t.Fatalf("expect no changes after round trip, got adds %v, removes %v\n%s", adds, removes, content)
+	}
+}
+
+func TestSgRuleSpecIcmpInvalid(t *testing.T) {
+	code := 0
+	for _, spec := range []SgRuleSpec{
+		{Direction: "ingress", Protocol: "icmp", PortRange: "8:0"},
+		{Direction: "ingress", Protocol: "icmp", IcmpCode: &code},
+		{Direction: "ingress", Protocol: "tcp", IcmpCode: &code},
+	} {
+		if _, err := spec.toRule(nil); err == nil {
+			t.Errorf("expect error for rule %s", spec)
+		}
+	}
+}
@@ -3,14 +3,24 @@ package neutron
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
 	"github.com/BytemanD/skyman/utility"
-	"github.com/spf13/cobra"
 )
 
 var Subnet = &cobra.Command{Use: "subnet"}
 
+const allocationPoolUsage = "Allocation pool IP addresses, format: start=<ip-address>,end=<ip-address>"
+const hostRouteUsage = "Additional route for the subnet, format: destination=<cidr>,gateway=<ip-address>"
+
+var ipv6Modes = []string{"dhcpv6-stateful", "dhcpv6-stateless", "slaac"}
+
 var subnetList = &cobra.Command{
 	Use:   "list",
 	Short: "List subnets",
@@ -29,34 +39,215 @@ var subnetList = &cobra.Command{
 		common.PrintSubnets(subnets, long)
 	},
 }
+
+func parseAllocationPools(values []string) ([]neutron.AllocationPool, error) {
+	pools := []neutron.AllocationPool{}
+	for _, value := range values {
+		kv, err := parseKeyValues(value, map[string][]string{"start": {"start"}, "end": {"end"}})
+		if err != nil {
+			return nil, err
+		}
+		if kv["start"] == "" || kv["end"] == "" {
+			return nil, fmt.Errorf("start and end are required in '%s'", value)
+		}
+		pools = append(pools, neutron.AllocationPool{Start: kv["start"], End: kv["end"]})
+	}
+	return pools, nil
+}
+func parseHostRoutes(values []string) ([]neutron.HostRouter, error) {
+	routes, err := parseRoutes(values)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(routes, func(route neutron.Route, _ int) neutron.HostRouter {
+		return neutron.HostRouter{Destination: route.Destination, NextHop: route.Nexthop}
+	}), nil
+}
+
+// 创建和更新子网的通用参数, 更新时 subnet 不为空, 新的地址池、DNS 和路由追加到已有的配置中
+func getSubnetParams(cmd *cobra.Command, subnet *neutron.Subnet) (map[string]any, error) {
+	params := map[string]any{}
+	if cmd.Flags().Changed("description") {
+		params["description"], _ = cmd.Flags().GetString("description")
+	}
+	if gateway, _ := cmd.Flags().GetString("gateway"); gateway != "" {
+		params["gateway_ip"] = gateway
+	}
+	if noGateway, _ := cmd.Flags().GetBool("no-gateway"); noGateway {
+		params["gateway_ip"] = nil
+	}
+	if poolValues, _ := cmd.Flags().GetStringArray("allocation-pool"); len(poolValues) > 0 {
+		pools, err := parseAllocationPools(poolValues)
+		if err != nil {
+			return nil, err
+		}
+		if subnet != nil {
+			pools = append(subnet.AllocationPools, pools...)
+		}
+		params["allocation_pools"] = pools
+	}
+	if nameservers, _ := cmd.Flags().GetStringArray("dns-nameserver"); len(nameservers) > 0 {
+		if subnet != nil {
+			nameservers = lo.Uniq(append(subnet.DnsNameservers, nameservers...))
+		}
+		params["dns_nameservers"] = nameservers
+	}
+	if routeValues, _ := cmd.Flags().GetStringArray("host-route"); len(routeValues) > 0 {
+		routes, err := parseHostRoutes(routeValues)
+		if err != nil {
+			return nil, err
+		}
+		if subnet != nil {
+			routes = append(subnet.HostRouters, routes...)
+		}
+		params["host_routes"] = routes
+	}
+	for flag, value := range map[string]bool{"dhcp": true, "no-dhcp": false} {
+		if set, _ := cmd.Flags().GetBool(flag); set {
+			params["enable_dhcp"] = value
+		}
+	}
+	return params, nil
+}
+
+func registerSubnetFlags(cmd *cobra.Command) {
+	cmd.Flags().String("description", "", "Set subnet description")
+	cmd.Flags().String("gateway", "", "Gateway IP address of the subnet")
+	cmd.Flags().Bool("no-gateway", false, "Do not configure a gateway for the subnet")
+	cmd.Flags().StringArray("allocation-pool", []string{}, allocationPoolUsage)
+	cmd.Flags().StringArray("dns-nameserver", []string{}, "DNS server for the subnet")
+	cmd.Flags().StringArray("host-route", []string{}, hostRouteUsage)
+	cmd.Flags().Bool("dhcp", false, "Enable DHCP")
+	cmd.Flags().Bool("no-dhcp", false, "Disable DHCP")
+	cmd.MarkFlagsMutuallyExclusive("gateway", "no-gateway")
+	cmd.MarkFlagsMutuallyExclusive("dhcp", "no-dhcp")
+}
+
 var subnetCreate = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create subnet",
-	Args:  cobra.ExactArgs(1),
+	Example: "subnet create subnet1 --network net1 --cidr 192.168.1.0/24 --dns-nameserver 8.8.8.8\n" +
+		"subnet create subnet1 --network net1 --cidr 192.168.1.0/24 --allocation-pool start=192.168.1.10,end=192.168.1.100\n" +
+		"subnet create subnet6 --network net1 --cidr fd00::/64 --ipv6-ra-mode slaac --ipv6-address-mode slaac\n" +
+		"subnet create subnet6 --network net1 --subnet-pool pool6 --prefix-length 64",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		cidr, _ := cmd.Flags().GetString("cidr")
+		subnetPool, _ := cmd.Flags().GetString("subnet-pool")
+		if cidr == "" && subnetPool == "" {
+			return fmt.Errorf("--cidr or --subnet-pool is required")
+		}
+		for _, flag := range []string{"ipv6-ra-mode", "ipv6-address-mode"} {
+			if mode, _ := cmd.Flags().GetString(flag); mode != "" && !lo.Contains(ipv6Modes, mode) {
+				return fmt.Errorf("invalid --%s, valid values: %v", flag, ipv6Modes)
+			}
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		c := common.DefaultClient().NeutronV2()
 
-		noDhcp, _ := cmd.Flags().GetBool("no-dhcp")
-		description, _ := cmd.Flags().GetString("description")
 		netIdOrName, _ := cmd.Flags().GetString("network")
 		cidr, _ := cmd.Flags().GetString("cidr")
 		ipVersion, _ := cmd.Flags().GetInt("ip-version")
+		subnetPool, _ := cmd.Flags().GetString("subnet-pool")
+		prefixLength, _ := cmd.Flags().GetInt("prefix-length")
+		ipv6RaMode, _ := cmd.Flags().GetString("ipv6-ra-mode")
+		ipv6AddressMode, _ := cmd.Flags().GetString("ipv6-address-mode")
 
 		network, err := c.FindNetwork(netIdOrName)
 		utility.LogError(err, "get network failed", true)
 
-		subnet, err := c.CreateSubnet(map[string]any{
-			"name":        args[0],
-			"cidr":        cidr,
-			"network_id":  network.Id,
-			"ip_version":  ipVersion,
-			"enable_dhcp": !noDhcp,
-			"description": description,
-		})
+		params, err := getSubnetParams(cmd, nil)
+		utility.LogError(err, "invalid subnet options", true)
+		params["name"] = args[0]
+		params["network_id"] = network.Id
+		if _, ok := params["enable_dhcp"]; !ok {
+			params["enable_dhcp"] = true
+		}
+		// 未指定 IP 版本时根据 CIDR 或子网池判断
+		if !cmd.Flags().Changed("ip-version") && strings.Contains(cidr, ":") {
+			ipVersion = 6
+		}
+		if cidr != "" {
+			params["cidr"] = cidr
+		}
+		if subnetPool != "" {
+			pool, err := c.FindSubnetPool(subnetPool)
+			utility.LogIfError(err, true, "get subnet pool %s failed", subnetPool)
+			params["subnetpool_id"] = pool.Id
+			if !cmd.Flags().Changed("ip-version") && pool.IpVersion != 0 {
+				ipVersion = pool.IpVersion
+			}
+		}
+		if prefixLength > 0 {
+			params["prefixlen"] = prefixLength
+		}
+		params["ip_version"] = ipVersion
+		if ipv6RaMode != "" {
+			params["ipv6_ra_mode"] = ipv6RaMode
+		}
+		if ipv6AddressMode != "" {
+			params["ipv6_address_mode"] = ipv6AddressMode
+		}
+		subnet, err := c.CreateSubnet(params)
 		utility.LogError(err, "create subnet failed", true)
 		common.PrintSubnet(*subnet)
 	},
 }
+var subnetSet = &cobra.Command{
+	Use:   "set <subnet>",
+	Short: "Set subnet properties",
+	Long: "Set subnet properties, allocation pools, DNS servers and host routes are appended to\n" +
+		"the existing ones unless --no-allocation-pool, --no-dns-nameservers or --no-host-route is specified.",
+	Example: "subnet set subnet1 --dns-nameserver 8.8.8.8 --host-route destination=10.0.0.0/8,gateway=192.168.1.254\n" +
+		"subnet set subnet1 --no-dns-nameservers --dns-nameserver 114.114.114.114",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient().NeutronV2()
+
+		subnet, err := c.FindSubnet(args[0])
+		utility.LogIfError(err, true, "get subnet %s failed", args[0])
+
+		noPool, _ := cmd.Flags().GetBool("no-allocation-pool")
+		noDns, _ := cmd.Flags().GetBool("no-dns-nameservers")
+		noRoute, _ := cmd.Flags().GetBool("no-host-route")
+		// 先清空已有的配置, 再追加新的配置
+		current := *subnet
+		if noPool {
+			current.AllocationPools = []neutron.AllocationPool{}
+		}
+		if noDns {
+			current.DnsNameservers = []string{}
+		}
+		if noRoute {
+			current.HostRouters = []neutron.HostRouter{}
+		}
+		params, err := getSubnetParams(cmd, &current)
+		utility.LogError(err, "invalid subnet options", true)
+		if cmd.Flags().Changed("name") {
+			params["name"], _ = cmd.Flags().GetString("name")
+		}
+		if _, ok := params["allocation_pools"]; noPool && !ok {
+			params["allocation_pools"] = current.AllocationPools
+		}
+		if _, ok := params["dns_nameservers"]; noDns && !ok {
+			params["dns_nameservers"] = current.DnsNameservers
+		}
+		if _, ok := params["host_routes"]; noRoute && !ok {
+			params["host_routes"] = current.HostRouters
+		}
+		if len(params) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		subnet, err = c.UpdateSubnet(subnet.Id, params)
+		utility.LogError(err, "update subnet failed", true)
+		common.PrintSubnet(*subnet)
+	},
+}
 var subnetShow = &cobra.Command{
 	Use:   "show <subnet>",
 	Short: "Show subnet",
@@ -88,14 +279,21 @@ func init() {
 	subnetList.Flags().BoolP("long", "l", false, "List additional fields in output")
 	subnetList.Flags().StringP("name", "n", "", "Search by router name")
 
-	subnetCreate.Flags().String("description", "", "Set subnet description")
-	subnetCreate.Flags().String("network", "", "Set subnet description")
+	subnetCreate.Flags().String("network", "", "Network this subnet belongs to (name or ID)")
 	subnetCreate.Flags().String("cidr", "", "Subnet range in CIDR notation")
-	subnetCreate.Flags().Bool("no-dhcp", false, "Disable DHCP")
-	subnetCreate.Flags().Int("ip-version", 4, "IP version (default is 4).")
-
+	subnetCreate.Flags().Int("ip-version", 4, "IP version, IPv6 is used if --cidr or --subnet-pool is IPv6")
+	subnetCreate.Flags().String("subnet-pool", "", "Subnet pool from which this subnet will obtain a CIDR (name or ID)")
+	subnetCreate.Flags().Int("prefix-length", 0, "Prefix length for subnet allocation from subnet pool")
+	subnetCreate.Flags().String("ipv6-ra-mode", "", fmt.Sprintf("IPv6 RA mode, %v", ipv6Modes))
+	subnetCreate.Flags().String("ipv6-address-mode", "", fmt.Sprintf("IPv6 address mode, %v", ipv6Modes))
+	registerSubnetFlags(subnetCreate)
 	subnetCreate.MarkFlagRequired("network")
-	subnetCreate.MarkFlagRequired("cidr")
 
-	Subnet.AddCommand(subnetList, subnetCreate, subnetDelete, subnetShow)
+	subnetSet.Flags().String("name", "", "New name of the subnet")
+	subnetSet.Flags().Bool("no-allocation-pool", false, "Clear existing allocation pools")
+	subnetSet.Flags().Bool("no-dns-nameservers", false, "Clear existing DNS servers")
+	subnetSet.Flags().Bool("no-host-route", false, "Clear existing host routes")
+	registerSubnetFlags(subnetSet)
+
+	Subnet.AddCommand(subnetList, subnetCreate, subnetSet, subnetDelete, subnetShow)
 }
@@ -0,0 +1,231 @@
+package neutron
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const subportUsage = "Subport to add, format: port=<port>,segmentation-type=<type>,segmentation-id=<id>"
+
+var trunk = &cobra.Command{Use: "trunk"}
+
+// 解析子端口参数, segmentation-type 默认为 vlan
+func parseSubports(client *openstack.Openstack, values []string) ([]neutron.SubPort, error) {
+	subPorts := []neutron.SubPort{}
+	for _, value := range values {
+		kv, err := parseKeyValues(value, map[string][]string{
+			"port":              {"port", "port-id"},
+			"segmentation-type": {"segmentation-type", "type"},
+			"segmentation-id":   {"segmentation-id", "id"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if kv["port"] == "" {
+			return nil, fmt.Errorf("port is required in '%s'", value)
+		}
+		subPort := neutron.SubPort{SegmentationType: kv["segmentation-type"]}
+		if subPort.SegmentationType == "" {
+			subPort.SegmentationType = "vlan"
+		}
+		if kv["segmentation-id"] != "" {
+			if subPort.SegmentationId, err = strconv.Atoi(kv["segmentation-id"]); err != nil {
+				return nil, fmt.Errorf("invalid segmentation id in '%s'", value)
+			}
+		} else if subPort.SegmentationType != "inherit" {
+			return nil, fmt.Errorf("segmentation-id is required in '%s'", value)
+		}
+		port, err := client.NeutronV2().FindPort(kv["port"])
+		if err != nil {
+			return nil, fmt.Errorf("get port %s failed: %w", kv["port"], err)
+		}
+		subPort.PortId = port.Id
+		subPorts = append(subPorts, subPort)
+	}
+	return subPorts, nil
+}
+
+var trunkList = &cobra.Command{
+	Use:   "list",
+	Short: "List trunks",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		c := common.DefaultClient()
+
+		long, _ := cmd.Flags().GetBool("long")
+		name, _ := cmd.Flags().GetString("name")
+		parentPort, _ := cmd.Flags().GetString("parent-port")
+
+		query := url.Values{}
+		if name != "" {
+			query.Set("name", name)
+		}
+		if parentPort != "" {
+			port, err := c.NeutronV2().FindPort(parentPort)
+			utility.LogIfError(err, true, "get port %s failed", parentPort)
+			query.Set("port_id", port.Id)
+		}
+		trunks, err := c.NeutronV2().ListTrunk(query)
+		utility.LogError(err, "list trunks failed", true)
+		common.PrintTrunks(trunks, long)
+	},
+}
+var trunkShow = &cobra.Command{
+	Use:   "show <trunk>",
+	Short: "Show trunk",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		trunk, err := c.NeutronV2().FindTrunk(args[0])
+		utility.LogIfError(err, true, "get trunk %s failed", args[0])
+		common.PrintTrunk(*trunk)
+	},
+}
+var trunkCreate = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create trunk",
+	Example: "network trunk create trunk1 --parent-port port1\n" +
+		"network trunk create trunk1 --parent-port port1 --subport port=port2,segmentation-id=100",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		parentPort, _ := cmd.Flags().GetString("parent-port")
+		description, _ := cmd.Flags().GetString("description")
+		subportValues, _ := cmd.Flags().GetStringArray("subport")
+		disable, _ := cmd.Flags().GetBool("disable")
+
+		port, err := c.NeutronV2().FindPort(parentPort)
+		utility.LogIfError(err, true, "get port %s failed", parentPort)
+		params := map[string]any{"name": args[0], "port_id": port.Id}
+		if description != "" {
+			params["description"] = description
+		}
+		if disable {
+			params["admin_state_up"] = false
+		}
+		if len(subportValues) > 0 {
+			subPorts, err := parseSubports(c, subportValues)
+			utility.LogError(err, "invalid subport", true)
+			params["sub_ports"] = subPorts
+		}
+		trunk, err := c.NeutronV2().CreateTrunk(params)
+		utility.LogError(err, "create trunk failed", true)
+		common.PrintTrunk(*trunk)
+	},
+}
+var trunkSet = &cobra.Command{
+	Use:     "set <trunk>",
+	Short:   "Set trunk properties or add subports",
+	Example: "network trunk set trunk1 --subport port=port2,segmentation-type=vlan,segmentation-id=100",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		subportValues, _ := cmd.Flags().GetStringArray("subport")
+		params := map[string]any{}
+		if cmd.Flags().Changed("name") {
+			params["name"], _ = cmd.Flags().GetString("name")
+		}
+		if cmd.Flags().Changed("description") {
+			params["description"], _ = cmd.Flags().GetString("description")
+		}
+		for flag, value := range map[string]bool{"enable": true, "disable": false} {
+			if set, _ := cmd.Flags().GetBool(flag); set {
+				params["admin_state_up"] = value
+			}
+		}
+		if len(params) == 0 && len(subportValues) == 0 {
+			console.Warn("nothing to do")
+			return
+		}
+		trunk, err := c.NeutronV2().FindTrunk(args[0])
+		utility.LogIfError(err, true, "get trunk %s failed", args[0])
+		if len(params) > 0 {
+			trunk, err = c.NeutronV2().UpdateTrunk(trunk.Id, params)
+			utility.LogError(err, "update trunk failed", true)
+		}
+		if len(subportValues) > 0 {
+			subPorts, err := parseSubports(c, subportValues)
+			utility.LogError(err, "invalid subport", true)
+			trunk, err = c.NeutronV2().TrunkAddSubports(trunk.Id, subPorts)
+			utility.LogError(err, "add subports failed", true)
+		}
+		common.PrintTrunk(*trunk)
+	},
+}
+var trunkUnset = &cobra.Command{
+	Use:   "unset <trunk>",
+	Short: "Remove subports from trunk",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		subports, _ := cmd.Flags().GetStringArray("subport")
+		trunk, err := c.NeutronV2().FindTrunk(args[0])
+		utility.LogIfError(err, true, "get trunk %s failed", args[0])
+		portIds := []string{}
+		for _, idOrName := range subports {
+			port, err := c.NeutronV2().FindPort(idOrName)
+			utility.LogIfError(err, true, "get port %s failed", idOrName)
+			portIds = append(portIds, port.Id)
+		}
+		trunk, err = c.NeutronV2().TrunkRemoveSubports(trunk.Id, portIds)
+		utility.LogError(err, "remove subports failed", true)
+		common.PrintTrunk(*trunk)
+	},
+}
+var trunkDelete = &cobra.Command{
+	Use:   "delete <trunk> [trunk ...]",
+	Short: "Delete trunk(s)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		c := common.DefaultClient()
+
+		for _, idOrName := range args {
+			trunk, err := c.NeutronV2().FindTrunk(idOrName)
+			if err != nil {
+				utility.LogError(err, fmt.Sprintf("get trunk %s failed", idOrName), false)
+				continue
+			}
+			fmt.Printf("Reqeust to delete trunk %s\n", idOrName)
+			err = c.NeutronV2().DeleteTrunk(trunk.Id)
+			utility.LogIfError(err, false, "delete trunk %s failed", idOrName)
+		}
+	},
+}
+
+func init() {
+	trunkList.Flags().BoolP("long", "l", false, "List additional fields in output")
+	trunkList.Flags().String("name", "", "Search by trunk name")
+	trunkList.Flags().String("parent-port", "", "Search by parent port (name or ID)")
+
+	trunkCreate.Flags().String("parent-port", "", "Parent port of the trunk (name or ID)")
+	trunkCreate.Flags().String("description", "", "Description of the trunk")
+	trunkCreate.Flags().StringArray("subport", []string{}, subportUsage)
+	trunkCreate.Flags().Bool("disable", false, "Disable trunk")
+	trunkCreate.MarkFlagRequired("parent-port")
+
+	trunkSet.Flags().String("name", "", "New name of the trunk")
+	trunkSet.Flags().String("description", "", "New description of the trunk")
+	trunkSet.Flags().StringArray("subport", []string{}, subportUsage)
+	trunkSet.Flags().Bool("enable", false, "Enable trunk")
+	trunkSet.Flags().Bool("disable", false, "Disable trunk")
+	trunkSet.MarkFlagsMutuallyExclusive("enable", "disable")
+
+	trunkUnset.Flags().StringArray("subport", []string{}, "Subport to remove (name or ID of port)")
+	trunkUnset.MarkFlagRequired("subport")
+
+	trunk.AddCommand(trunkList, trunkShow, trunkCreate, trunkSet, trunkUnset, trunkDelete)
+	Network.AddCommand(trunk)
+}
@@ -3,12 +3,14 @@ package nova
 import (
 	"strings"
 
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
 	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/cmd/flags"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
 	"github.com/BytemanD/skyman/openstack/model/nova"
 	"github.com/BytemanD/skyman/utility"
 )
@@ -46,6 +48,113 @@ var aggShow = &cobra.Command{
 		aggregate, err := client.NovaV2().FindAgg(args[0])
 		utility.LogIfError(err, true, "get aggregate %s failed", args[0])
 		common.PrintAggregate(*aggregate)
+		if len(aggregate.Hosts) == 0 {
+			return
+		}
+		services, err := client.NovaV2().ListComputeService()
+		utility.LogError(err, "list compute services failed", true)
+		printAggregateHosts(*aggregate, services)
+	},
+}
+
+// 显示聚合中主机的计算服务状态
+func printAggregateHosts(aggregate nova.Aggregate, services []nova.Service) {
+	hostServices := lo.SliceToMap(services, func(s nova.Service) (string, nova.Service) {
+		return s.Host, s
+	})
+	items := lo.Map(aggregate.Hosts, func(host string, _ int) nova.Service {
+		if service, ok := hostServices[host]; ok {
+			return service
+		}
+		return nova.Service{Host: host, Status: "-", State: "-"}
+	})
+	common.PrintItems(
+		[]datatable.Column[nova.Service]{
+			{Name: "Host"}, {Name: "Zone"},
+			{Name: "Status", AutoColor: true}, {Name: "State", AutoColor: true},
+			{Name: "ForcedDown"}, {Name: "UpdatedAt"}, {Name: "DisabledReason"},
+		},
+		[]datatable.Column[nova.Service]{},
+		items, common.TableOptions{SortBy: []table.SortBy{{Name: "Host"}}},
+	)
+}
+
+func parseAggregateProperties(properties []string) map[string]string {
+	metadata := map[string]string{}
+	for _, property := range properties {
+		kv := strings.SplitN(property, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			console.Fatal("Invalid property %s, must be: key=value", property)
+		}
+		metadata[kv[0]] = kv[1]
+	}
+	return metadata
+}
+
+var aggSet = &cobra.Command{
+	Use:   "set <aggregate>",
+	Short: "Set aggregate properties, name or availability zone",
+	Example: "aggregate set agg1 --property ssd=true --property pinned=true\n" +
+		"aggregate set agg1 --name agg2 --zone az1",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		zone, _ := cmd.Flags().GetString("zone")
+		properties, _ := cmd.Flags().GetStringArray("property")
+		if name == "" && zone == "" && len(properties) == 0 {
+			console.Fatal("at least one of --name, --zone and --property is required")
+		}
+		metadata := parseAggregateProperties(properties)
+
+		client := common.DefaultClient()
+		aggregate, err := client.NovaV2().FindAgg(args[0])
+		utility.LogIfError(err, true, "get aggregate %s failed", args[0])
+		if name != "" || zone != "" {
+			aggregate, err = client.NovaV2().UpdateAgg(aggregate.Id, name, zone)
+			utility.LogIfError(err, true, "update aggregate %s failed", args[0])
+		}
+		if len(metadata) > 0 {
+			aggregate, err = client.NovaV2().AggSetMetadata(aggregate.Id, metadata)
+			utility.LogIfError(err, true, "set metadata of aggregate %s failed", args[0])
+		}
+		common.PrintAggregate(*aggregate)
+	},
+}
+var aggUnset = &cobra.Command{
+	Use:   "unset <aggregate>",
+	Short: "Unset aggregate properties",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		properties, _ := cmd.Flags().GetStringArray("property")
+		if len(properties) == 0 {
+			console.Fatal("at least one --property is required")
+		}
+		client := common.DefaultClient()
+		aggregate, err := client.NovaV2().FindAgg(args[0])
+		utility.LogIfError(err, true, "get aggregate %s failed", args[0])
+		aggregate, err = client.NovaV2().AggUnsetMetadata(aggregate.Id, properties)
+		utility.LogIfError(err, true, "unset metadata of aggregate %s failed", args[0])
+		common.PrintAggregate(*aggregate)
+	},
+}
+var aggCacheImages = &cobra.Command{
+	Use:   "cache-images <aggregate> <image> [<image> ...]",
+	Short: "Request image(s) be pre-cached on hosts in aggregate (requires micro version >= 2.81)",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		client := common.DefaultClient()
+		aggregate, err := client.NovaV2().FindAgg(args[0])
+		utility.LogIfError(err, true, "get aggregate %s failed", args[0])
+		imageIds := []string{}
+		for _, idOrName := range args[1:] {
+			image, err := client.GlanceV2().FindImage(idOrName)
+			utility.LogIfError(err, true, "get image %s failed", idOrName)
+			imageIds = append(imageIds, image.Id)
+		}
+		err = client.NovaV2().AggCacheImages(aggregate.Id, imageIds)
+		utility.LogIfError(err, true, "cache images on aggregate %s failed", args[0])
+		console.Info("Requested to cache %d image(s) on %d host(s) of aggregate %s",
+			len(imageIds), len(aggregate.Hosts), aggregate.Name)
 	},
 }
 var aggCreate = &cobra.Command{
@@ -135,7 +244,15 @@ func init() {
 		AZ: aggCreate.Flags().String("az", "", "The availability zone of the aggregate"),
 	}
 
+	aggSet.Flags().String("name", "", "New name of the aggregate")
+	aggSet.Flags().String("zone", "", "New availability zone of the aggregate")
+	aggSet.Flags().StringArray("property", []string{},
+		"Property to add or modify for this aggregate (repeat option to set multiple properties)")
+	aggUnset.Flags().StringArray("property", []string{},
+		"Property to remove from this aggregate (repeat option to remove multiple properties)")
+
 	aggAdd.AddCommand(addHost)
 	aggRemove.AddCommand(removeHost)
-	Aggregate.AddCommand(aggList, aggShow, aggCreate, aggDelete, aggAdd, aggRemove)
+	Aggregate.AddCommand(aggList, aggShow, aggCreate, aggDelete, aggAdd, aggRemove,
+		aggSet, aggUnset, aggCacheImages)
 }
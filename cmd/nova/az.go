@@ -1,11 +1,19 @@
 package nova
 
 import (
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/cmd/flags"
 	"github.com/BytemanD/skyman/cmd/views"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack/model/nova"
 	"github.com/BytemanD/skyman/utility"
 )
 
@@ -39,12 +47,77 @@ var azList = &cobra.Command{
 	},
 }
 
+type AZHostHealth struct {
+	Host       string
+	Aggregates string
+	Service    string
+	Status     string
+	Available  string
+	UpdatedAt  string
+}
+
+var azShow = &cobra.Command{
+	Use:   "show <zone>",
+	Short: "Show hosts, aggregates and service health of availability zone",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		client := common.DefaultClient()
+		azList, err := client.NovaV2().ListAZ(nil, true)
+		utility.LogError(err, "list availability zones failed", true)
+		zone, ok := lo.Find(azList, func(item nova.AvailabilityZone) bool {
+			return item.ZoneName == args[0]
+		})
+		if !ok {
+			console.Fatal("availability zone %s not found", args[0])
+		}
+		aggregates, err := client.NovaV2().ListAgg(nil)
+		utility.LogError(err, "list aggregates failed", true)
+
+		items := []AZHostHealth{}
+		for host, services := range zone.Hosts {
+			hostAggs := lo.FilterMap(aggregates, func(agg nova.Aggregate, _ int) (string, bool) {
+				return agg.Name, lo.Contains(agg.Hosts, host)
+			})
+			sort.Strings(hostAggs)
+			for name, service := range services {
+				item := AZHostHealth{
+					Host: host, Aggregates: strings.Join(hostAggs, "\n"),
+					Service: name, Status: "disabled", Available: "XXX",
+					UpdatedAt: service.UpdatedAt,
+				}
+				if service.Active {
+					item.Status = "enabled"
+				}
+				if service.Available {
+					item.Available = ":)"
+				}
+				items = append(items, item)
+			}
+		}
+		zoneState := "available"
+		if !zone.ZoneState.Available {
+			zoneState = "not available"
+		}
+		console.Info("zone %s is %s, %d host(s)", zone.ZoneName, zoneState, len(zone.Hosts))
+		common.PrintItems(
+			[]datatable.Column[AZHostHealth]{
+				{Name: "Host"}, {Name: "Aggregates"}, {Name: "Service"},
+				{Name: "Status", AutoColor: true}, {Name: "Available", AutoColor: true},
+				{Name: "UpdatedAt"},
+			},
+			[]datatable.Column[AZHostHealth]{},
+			items,
+			common.TableOptions{SeparateRows: true, SortBy: []table.SortBy{{Name: "Host"}, {Name: "Service"}}},
+		)
+	},
+}
+
 func init() {
 	// flavor list flags
 	azListFlags = flags.AZListFlags{
 		Tree: azList.Flags().Bool("tree", false, "Show tree view."),
 	}
 
-	AZ.AddCommand(azList)
+	AZ.AddCommand(azList, azShow)
 
 }
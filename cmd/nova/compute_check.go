@@ -0,0 +1,186 @@
+package nova
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/common/i18n"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const (
+	HEALTH_OK          = "ok"
+	HEALTH_DISABLED    = "disabled"
+	HEALTH_FORCED_DOWN = "forced_down"
+	HEALTH_STALE       = "stale"
+	HEALTH_DOWN        = "down"
+	HEALTH_DEAD        = "dead"
+)
+
+var unhealthyStates = []string{HEALTH_STALE, HEALTH_DOWN, HEALTH_DEAD}
+
+type ServiceHealth struct {
+	nova.Service
+	Age    time.Duration
+	Health string
+}
+
+func (s ServiceHealth) IsUnhealthy() bool {
+	return lo.Contains(unhealthyStates, s.Health)
+}
+
+// 服务上报时间距今的时长
+func serviceAge(service nova.Service, now time.Time) time.Duration {
+	if updatedAt, err := utility.ParseUTCTime(service.UpdatedAt); err == nil {
+		return now.Sub(updatedAt)
+	}
+	return time.Duration(math.MaxInt64)
+}
+
+// 根据 forced_down、state、status 以及上报时间判断服务的健康状态
+func classifyService(service nova.Service, now time.Time, staleAfter, deadAfter time.Duration) ServiceHealth {
+	item := ServiceHealth{Service: service, Age: serviceAge(service, now)}
+	switch {
+	case service.ForcedDown:
+		item.Health = HEALTH_FORCED_DOWN
+	case service.State == "down" && item.Age >= deadAfter:
+		item.Health = HEALTH_DEAD
+	case service.State == "down":
+		item.Health = HEALTH_DOWN
+	case item.Age >= staleAfter:
+		item.Health = HEALTH_STALE
+	case service.Status == "disabled":
+		item.Health = HEALTH_DISABLED
+	default:
+		item.Health = HEALTH_OK
+	}
+	return item
+}
+
+func humanAge(age time.Duration) string {
+	if age == time.Duration(math.MaxInt64) {
+		return "-"
+	}
+	return age.Truncate(time.Second).String()
+}
+
+// 将长时间无心跳的计算服务设置为 forced_down, 以便疏散虚拟机
+func forceDownServices(items []ServiceHealth, yes bool) {
+	deadComputes := lo.Filter(items, func(item ServiceHealth, _ int) bool {
+		return item.Binary == "nova-compute" && item.Health == HEALTH_DEAD
+	})
+	if len(deadComputes) == 0 {
+		console.Info("no dead compute service need to be forced down")
+		return
+	}
+	hosts := lo.Map(deadComputes, func(item ServiceHealth, _ int) string { return item.Host })
+	if !yes {
+		fmt.Printf("dead compute services: %s\n", strings.Join(hosts, ", "))
+		if !utility.DefaultScanComfirm(fmt.Sprintf("force down %d compute service(s)", len(hosts))) {
+			return
+		}
+	}
+	client := common.DefaultClient()
+	for _, host := range hosts {
+		if _, err := client.NovaV2().DownService(host, "nova-compute"); err != nil {
+			console.Error("force down compute service of %s failed: %s", host, err)
+		} else {
+			console.Success("forced down compute service of %s", host)
+		}
+	}
+}
+
+var csCheck = &cobra.Command{
+	Use:   "check",
+	Short: "Check health of compute services",
+	Long: "Check health of compute services, exit with non-zero code if any service is unhealthy.\n" +
+		"Health of service:\n" +
+		"  ok:          service is up and enabled\n" +
+		"  disabled:    service is up but disabled\n" +
+		"  forced_down: service is forced down\n" +
+		"  stale:       service is up but not updated for --stale-after, heartbeats are lagging before\n" +
+		"               nova reports it down after service_down_time (default 60s) (unhealthy)\n" +
+		"  down:        service is down (unhealthy)\n" +
+		"  dead:        service is down and not updated for --dead-after (unhealthy)",
+	Example: "compute service check\n" +
+		"compute service check --binary nova-compute --stale-after 40s\n" +
+		"compute service check --force-down-stale --dead-after 1h",
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		binaries, _ := cmd.Flags().GetStringSlice("binary")
+		staleAfter, _ := cmd.Flags().GetDuration("stale-after")
+		deadAfter, _ := cmd.Flags().GetDuration("dead-after")
+		forceDownStale, _ := cmd.Flags().GetBool("force-down-stale")
+		yes, _ := cmd.Flags().GetBool("yes")
+		all, _ := cmd.Flags().GetBool("all")
+
+		client := common.DefaultClient()
+		services, err := client.NovaV2().ListService(nil)
+		utility.LogError(err, "list services failed", true)
+
+		now := time.Now().UTC()
+		items := []ServiceHealth{}
+		for _, service := range services {
+			if len(binaries) > 0 && !lo.Contains(binaries, service.Binary) {
+				continue
+			}
+			items = append(items, classifyService(service, now, staleAfter, deadAfter))
+		}
+		counts := lo.CountValuesBy(items, func(item ServiceHealth) string { return item.Health })
+		unhealthy := lo.CountBy(items, func(item ServiceHealth) bool { return item.IsUnhealthy() })
+
+		printItems := items
+		if !all {
+			printItems = lo.Filter(items, func(item ServiceHealth, _ int) bool { return item.Health != HEALTH_OK })
+		}
+		common.PrintItems(
+			[]datatable.Column[ServiceHealth]{
+				{Name: "Binary"}, {Name: "Host"}, {Name: "Zone"},
+				{Name: "Status", AutoColor: true}, {Name: "State", AutoColor: true},
+				{Name: "ForcedDown"}, {Name: "UpdatedAt"},
+				{Name: "Age", RenderFunc: func(item ServiceHealth) any { return humanAge(item.Age) }},
+				{Name: "Health", AutoColor: true},
+			},
+			[]datatable.Column[ServiceHealth]{},
+			printItems,
+			common.TableOptions{SortBy: []table.SortBy{{Name: "Binary"}, {Name: "Host"}}},
+		)
+		summary := lo.Map(
+			[]string{HEALTH_OK, HEALTH_DISABLED, HEALTH_FORCED_DOWN, HEALTH_STALE, HEALTH_DOWN, HEALTH_DEAD},
+			func(health string, _ int) string { return fmt.Sprintf("%s=%d", health, counts[health]) },
+		)
+		console.Info("%d service(s): %s", len(items), strings.Join(summary, ", "))
+
+		if forceDownStale {
+			forceDownServices(items, yes)
+		}
+		if unhealthy > 0 {
+			console.Error("%d service(s) are unhealthy", unhealthy)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	csCheck.Flags().StringSlice("binary", []string{"nova-compute", "nova-conductor", "nova-scheduler"},
+		"Binaries to check")
+	csCheck.Flags().Duration("stale-after", time.Second*30,
+		"Service is stale if it is not updated for this time, it should be less than service_down_time of nova")
+	csCheck.Flags().Duration("dead-after", time.Minute*30, "Service which is down is dead if it is not updated for this time")
+	csCheck.Flags().Bool("force-down-stale", false, "Force down dead compute services, so that servers can be evacuated")
+	csCheck.Flags().BoolP("yes", "y", false, i18n.T("answerYes"))
+	csCheck.Flags().Bool("all", false, "Show all services, including healthy ones")
+
+	computeService.AddCommand(csCheck)
+}
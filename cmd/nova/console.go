@@ -2,11 +2,16 @@ package nova
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/cmd/flags"
 	"github.com/BytemanD/skyman/common"
 	"github.com/BytemanD/skyman/utility"
@@ -17,6 +22,46 @@ var (
 )
 var Console = &cobra.Command{Use: "console"}
 
+// 输出跟踪到的控制台日志, 首次输出时只显示最后 lines 行
+//
+// 末尾未换行的内容(例如登录提示符)直接输出, 换行后只输出剩余的部分
+type consoleLogPrinter struct {
+	output  io.Writer
+	lines   int
+	printed bool
+	partial string
+}
+
+func (p *consoleLogPrinter) Print(lines []string, pending string) {
+	if !p.printed && p.lines > 0 && len(lines) > p.lines {
+		lines = lines[len(lines)-p.lines:]
+	}
+	p.printed = true
+	for _, line := range lines {
+		if p.partial != "" {
+			if strings.HasPrefix(line, p.partial) {
+				line = line[len(p.partial):]
+			} else {
+				fmt.Fprintln(p.output)
+			}
+			p.partial = ""
+		}
+		fmt.Fprintln(p.output, line)
+	}
+	if pending == p.partial {
+		return
+	}
+	if p.partial != "" && strings.HasPrefix(pending, p.partial) {
+		fmt.Fprint(p.output, pending[len(p.partial):])
+	} else {
+		if p.partial != "" {
+			fmt.Fprintln(p.output)
+		}
+		fmt.Fprint(p.output, pending)
+	}
+	p.partial = pending
+}
+
 var consoleLog = &cobra.Command{
 	Use:   "log <server>",
 	Short: "Show console log of server",
@@ -26,9 +71,35 @@ var consoleLog = &cobra.Command{
 
 		server, err := client.NovaV2().FindServer(args[0])
 		utility.LogError(err, "get server failed", true)
-		consoleLog, err := client.NovaV2().GetServerConsoleLog(server.Id, *consoleLogFlags.Lines)
-		utility.LogError(err, "get console log failed", true)
-		println(consoleLog.Output)
+		if !*consoleLogFlags.Follow && *consoleLogFlags.WaitFor == "" {
+			consoleLog, err := client.NovaV2().GetServerConsoleLog(server.Id, *consoleLogFlags.Lines)
+			utility.LogError(err, "get console log failed", true)
+			println(consoleLog.Output)
+			return
+		}
+		printer := &consoleLogPrinter{output: os.Stdout, lines: int(*consoleLogFlags.Lines)}
+		if *consoleLogFlags.WaitFor != "" {
+			pattern, err := regexp.Compile(*consoleLogFlags.WaitFor)
+			utility.LogError(err, "invalid --wait-for", true)
+			var onLines func([]string, string)
+			if *consoleLogFlags.Follow {
+				onLines = printer.Print
+			}
+			err = client.NovaV2().WaitConsoleLogMatch(server.Id, pattern, *consoleLogFlags.Timeout, onLines)
+			utility.LogError(err, "wait console log failed", true)
+			console.Info("[%s] console log matched '%s'", server.Id, pattern)
+			return
+		}
+		follower := client.NovaV2().FollowServerConsoleLog(server.Id)
+		for {
+			lines, pending, err := follower.Next()
+			if err != nil {
+				console.Warn("get console log failed: %s", err)
+			} else {
+				printer.Print(lines, pending)
+			}
+			time.Sleep(*consoleLogFlags.Interval)
+		}
 	},
 }
 
@@ -65,7 +136,12 @@ var consoleUrl = &cobra.Command{
 
 func init() {
 	consoleLogFlags = flags.ConsoleLogFlags{
-		Lines: consoleLog.Flags().UintP("lines", "l", 0, "Number of lines to display from the end of the log"),
+		Lines:  consoleLog.Flags().UintP("lines", "l", 0, "Number of lines to display from the end of the log"),
+		Follow: consoleLog.Flags().Bool("follow", false, "Follow console log output"),
+		WaitFor: consoleLog.Flags().String("wait-for", "",
+			"Wait until console log matches the regex, e.g. ' login:'"),
+		Timeout:  consoleLog.Flags().Duration("timeout", time.Minute*10, "Timeout for --wait-for"),
+		Interval: consoleLog.Flags().Duration("interval", time.Second*2, "Interval to poll console log for --follow"),
 	}
 
 	Console.AddCommand(consoleLog, consoleUrl)
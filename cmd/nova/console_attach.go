@@ -0,0 +1,156 @@
+package nova
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/websocket"
+	"golang.org/x/term"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const DEFAULT_ESCAPE = "^]"
+
+// 解析 ^X 格式的退出字符
+func parseEscape(escape string) (byte, error) {
+	if len(escape) == 2 && escape[0] == '^' {
+		return strings.ToUpper(escape)[1] & 0x1f, nil
+	}
+	if len(escape) == 1 {
+		return escape[0], nil
+	}
+	return 0, fmt.Errorf("invalid escape %s, it must be a character or format like ^]", escape)
+}
+
+func dialSerialConsole(consoleUrl string) (*websocket.Conn, error) {
+	u, err := url.Parse(consoleUrl)
+	if err != nil {
+		return nil, err
+	}
+	// serial proxy 会检查 Origin 是否与代理地址一致
+	origin := url.URL{Scheme: "http", Host: u.Host}
+	if u.Scheme == "wss" {
+		origin.Scheme = "https"
+	}
+	config, err := websocket.NewConfig(consoleUrl, origin.String())
+	if err != nil {
+		return nil, err
+	}
+	config.Protocol = []string{"binary"}
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	conn.PayloadType = websocket.BinaryFrame
+	return conn, nil
+}
+
+// 将终端输入转发给控制台, 读到退出字符时返回
+func forwardInput(conn io.Writer, input io.Reader, escape byte) error {
+	buf := make([]byte, 1024)
+	for {
+		n, err := input.Read(buf)
+		if err != nil {
+			return err
+		}
+		data := buf[:n]
+		index := bytes.IndexByte(data, escape)
+		if index >= 0 {
+			data = data[:index]
+		}
+		if len(data) > 0 {
+			if _, err := conn.Write(data); err != nil {
+				return err
+			}
+		}
+		if index >= 0 {
+			return nil
+		}
+	}
+}
+
+// 返回控制台的输出, 指定 tee 文件时同时追加到文件中
+func openConsoleOutput(stdout io.Writer, teeFile string) (io.Writer, func(), error) {
+	if teeFile == "" {
+		return stdout, func() {}, nil
+	}
+	f, err := os.OpenFile(teeFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.MultiWriter(stdout, f), func() { f.Close() }, nil
+}
+
+func attachSerialConsole(consoleUrl string, escape byte, input io.Reader, output io.Writer) error {
+	conn, err := dialSerialConsole(consoleUrl)
+	if err != nil {
+		return fmt.Errorf("connect to %s failed: %w", consoleUrl, err)
+	}
+	defer conn.Close()
+
+	if f, ok := input.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		oldState, err := term.MakeRaw(int(f.Fd()))
+		if err != nil {
+			return err
+		}
+		defer term.Restore(int(f.Fd()), oldState)
+	}
+	done := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(output, conn)
+		done <- err
+	}()
+	go func() {
+		done <- forwardInput(conn, input, escape)
+	}()
+	return <-done
+}
+
+var consoleAttach = &cobra.Command{
+	Use:   "attach <server>",
+	Short: "Attach to serial console of server",
+	Long:  fmt.Sprintf("Attach to serial console of server, press %s to detach", DEFAULT_ESCAPE),
+	Example: "console attach vm1\n" +
+		"console attach vm1 --escape '^x' --tee vm1-console.log",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		escapeValue, _ := cmd.Flags().GetString("escape")
+		teeFile, _ := cmd.Flags().GetString("tee")
+
+		escape, err := parseEscape(escapeValue)
+		utility.LogError(err, "invalid escape", true)
+
+		client := common.DefaultClient()
+		server, err := client.NovaV2().FindServer(args[0])
+		utility.LogError(err, "get server failed", true)
+		serialConsole, err := client.NovaV2().GetServerConsoleUrl(server.Id, "serial")
+		utility.LogError(err, "get serial console failed", true)
+
+		output, closeOutput, err := openConsoleOutput(os.Stdout, teeFile)
+		utility.LogError(err, "open tee file failed", true)
+		defer closeOutput()
+		console.Info("connected to %s, press %s to detach", server.Name, escapeValue)
+		err = attachSerialConsole(serialConsole.Url, escape, os.Stdin, output)
+		fmt.Println()
+		if err != nil && err != io.EOF {
+			console.Error("console session closed: %s", err)
+			os.Exit(1)
+		}
+		console.Info("detached from %s", server.Name)
+	},
+}
+
+func init() {
+	consoleAttach.Flags().String("escape", DEFAULT_ESCAPE, "Escape character to detach from console")
+	consoleAttach.Flags().String("tee", "", "Append the console output to file")
+
+	Console.AddCommand(consoleAttach)
+}
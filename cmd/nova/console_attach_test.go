@@ -0,0 +1,141 @@
+package nova
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// 模拟 serial proxy: 连接后输出登录提示, 并回显收到的数据
+type fakeSerialProxy struct {
+	*httptest.Server
+	mu        sync.Mutex
+	protocols []string
+	received  bytes.Buffer
+}
+
+func newFakeSerialProxy() *fakeSerialProxy {
+	proxy := &fakeSerialProxy{}
+	proxy.Server = httptest.NewServer(websocket.Server{
+		Handshake: func(config *websocket.Config, _ *http.Request) error {
+			proxy.mu.Lock()
+			proxy.protocols = config.Protocol
+			proxy.mu.Unlock()
+			config.Protocol = []string{"binary"}
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			conn.PayloadType = websocket.BinaryFrame
+			conn.Write([]byte("login: "))
+			buf := make([]byte, 1024)
+			for {
+				n, err := conn.Read(buf)
+				if err != nil {
+					return
+				}
+				proxy.mu.Lock()
+				proxy.received.Write(buf[:n])
+				proxy.mu.Unlock()
+				conn.Write(buf[:n])
+			}
+		},
+	})
+	return proxy
+}
+func (p *fakeSerialProxy) Url() string {
+	return "ws" + strings.TrimPrefix(p.URL, "http")
+}
+
+func waitOutput(t *testing.T, output *syncBuffer, expect string) {
+	deadline := time.Now().Add(time.Second * 5)
+	for !strings.Contains(output.String(), expect) {
+		if time.Now().After(deadline) {
+			t.Fatalf("wait for output %q timeout, got %q", expect, output.String())
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestAttachSerialConsole(t *testing.T) {
+	proxy := newFakeSerialProxy()
+	defer proxy.Close()
+
+	teeFile := filepath.Join(t.TempDir(), "console.log")
+	stdout := &syncBuffer{}
+	output, closeOutput, err := openConsoleOutput(stdout, teeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	escape, _ := parseEscape(DEFAULT_ESCAPE)
+	input, inputWriter := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() { done <- attachSerialConsole(proxy.Url(), escape, input, output) }()
+
+	waitOutput(t, stdout, "login: ")
+	inputWriter.Write([]byte("root\r"))
+	waitOutput(t, stdout, "root\r")
+	// 退出字符以及之后的内容不应发送给控制台
+	inputWriter.Write([]byte("ls\x1dreboot\r"))
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expect detached without error, got %s", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("detach timeout")
+	}
+	closeOutput()
+
+	proxy.mu.Lock()
+	defer proxy.mu.Unlock()
+	if len(proxy.protocols) != 1 || proxy.protocols[0] != "binary" {
+		t.Errorf("expect subprotocol [binary], got %v", proxy.protocols)
+	}
+	if received := proxy.received.String(); strings.Contains(received, "reboot") {
+		t.Errorf("data after escape should not be sent, received %q", received)
+	}
+	content, err := os.ReadFile(teeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "login: root\r") {
+		t.Errorf("unexpected tee file content %q", content)
+	}
+}
+
+func TestForwardInputEscape(t *testing.T) {
+	conn := &bytes.Buffer{}
+	err := forwardInput(conn, strings.NewReader("echo hello\r\x1decho world\r"), 0x1d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.String() != "echo hello\r" {
+		t.Errorf("expect 'echo hello\\r', got %q", conn.String())
+	}
+}
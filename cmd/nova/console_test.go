@@ -0,0 +1,21 @@
+package nova
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestConsoleLogPrinterPending(t *testing.T) {
+	output := &bytes.Buffer{}
+	printer := &consoleLogPrinter{output: output, lines: 2}
+
+	printer.Print([]string{"line 1", "line 2", "line 3"}, "login: ")
+	printer.Print(nil, "login: ")
+	printer.Print([]string{"login: root"}, "Password: ")
+	printer.Print([]string{"Password: ", "reboot"}, "")
+
+	expect := "line 2\nline 3\nlogin: root\nPassword: \nreboot\n"
+	if output.String() != expect {
+		t.Errorf("expect %q, got %q", expect, output.String())
+	}
+}
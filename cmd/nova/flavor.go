@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
 	"github.com/BytemanD/go-console/console"
@@ -15,6 +16,7 @@ import (
 	"github.com/BytemanD/skyman/cmd/views"
 	"github.com/BytemanD/skyman/common"
 	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
 	"github.com/BytemanD/skyman/openstack/model/nova"
 	"github.com/BytemanD/skyman/utility"
 )
@@ -39,6 +41,28 @@ func getExtraSpecsMap(extraSpecs []string) nova.ExtraSpecs {
 	return extraSpecsMap
 }
 
+func getFlavorProjects(client *openstack.Openstack, flavor nova.Flavor) []string {
+	if flavor.IsPublic {
+		return nil
+	}
+	accessList, err := client.NovaV2().ListFlavorAccess(flavor.Id)
+	if err != nil {
+		console.Warn("list flavor access failed: %s", err)
+		return nil
+	}
+	return lo.Map(accessList, func(access nova.FlavorAccess, _ int) string {
+		return access.TenantId
+	})
+}
+
+func findProjectIds(client *openstack.Openstack, projects []string) []string {
+	return lo.Map(projects, func(idOrName string, _ int) string {
+		project, err := client.KeystoneV3().FindProject(idOrName)
+		utility.LogIfError(err, true, "get project %s failed", idOrName)
+		return project.Id
+	})
+}
+
 var flavorList = &cobra.Command{
 	Use:   "list",
 	Short: "List flavors",
@@ -145,7 +169,7 @@ var flavorShow = &cobra.Command{
 		idOrName := args[0]
 		flavor, err := client.NovaV2().FindFlavor(idOrName)
 		utility.LogIfError(err, true, "Show flavor failed")
-		views.PrintFlavor(*flavor)
+		views.PrintFlavor(*flavor, getFlavorProjects(client, *flavor)...)
 	},
 }
 var flavorDelete = &cobra.Command{
@@ -223,7 +247,7 @@ var flavorCreate = &cobra.Command{
 			}
 			flavor.ExtraSpecs = createdExtraSpecs
 		}
-		views.PrintFlavor(*flavor)
+		views.PrintFlavor(*flavor, getFlavorProjects(client, *flavor)...)
 	},
 }
 var flavorSet = &cobra.Command{
@@ -247,6 +271,15 @@ var flavorSet = &cobra.Command{
 		flavor, err := client.NovaV2().FindFlavor(idOrName)
 		utility.LogError(err, "Get flavor failed", true)
 
+		projectIds := findProjectIds(client, *flavorSetFlags.Projects)
+		if len(projectIds) > 0 && flavor.IsPublic {
+			console.Fatal("flavor %s is public, access can only be added to private flavor", flavor.Name)
+		}
+		for _, projectId := range projectIds {
+			_, err := client.NovaV2().AddFlavorAccess(flavor.Id, projectId)
+			utility.LogIfError(err, false, "add access for project %s failed", projectId)
+		}
+
 		flavorExtraSpecs, err := client.NovaV2().GetFlavorExtraSpecs(flavor.Id)
 		utility.LogError(err, "Get flavor extra specs failed", true)
 
@@ -264,6 +297,31 @@ var flavorSet = &cobra.Command{
 		}
 	},
 }
+var flavorUnset = &cobra.Command{
+	Use:   "unset <flavor id or name>",
+	Short: "Unset flavor properties or project access",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projects, _ := cmd.Flags().GetStringArray("project")
+		properties, _ := cmd.Flags().GetStringArray("property")
+
+		client := common.DefaultClient()
+		flavor, err := client.NovaV2().FindFlavor(args[0])
+		utility.LogError(err, "Get flavor failed", true)
+
+		for _, projectId := range findProjectIds(client, projects) {
+			_, err := client.NovaV2().RemoveFlavorAccess(flavor.Id, projectId)
+			utility.LogIfError(err, false, "remove access for project %s failed", projectId)
+		}
+		for _, property := range properties {
+			if _, ok := flavor.ExtraSpecs[property]; !ok {
+				continue
+			}
+			err := client.NovaV2().DeleteFlavorExtraSpec(flavor.Id, property)
+			utility.LogIfError(err, false, "delete extra spec %s failed", property)
+		}
+	},
+}
 
 func init() {
 	flavorListFlags = flags.FlavorListFlags{
@@ -294,8 +352,14 @@ func init() {
 			"Property to add or modify for the flavor(s) (repeat option to set multiple properties)"),
 		Unset: flavorSet.Flags().StringArray("unset", []string{},
 			"Property to remove for the flavor(s) (repeat option to set multiple properties)"),
+		Projects: flavorSet.Flags().StringArray("project", []string{},
+			"Allow project to access private flavor (name or ID, repeat option to set multiple projects)"),
 	}
+	flavorUnset.Flags().StringArray("project", []string{},
+		"Remove project access from private flavor (name or ID, repeat option to unset multiple projects)")
+	flavorUnset.Flags().StringArray("property", []string{},
+		"Property to remove from flavor (repeat option to unset multiple properties)")
 
 	Flavor.AddCommand(flavorList, flavorShow, flavorCreate, flavorDelete,
-		flavorSet)
+		flavorSet, flavorUnset)
 }
@@ -0,0 +1,479 @@
+package nova
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/openstack/model/placement"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const (
+	FIT_SOURCE_PLACEMENT  = "placement"
+	FIT_SOURCE_HYPERVISOR = "hypervisor"
+
+	AGG_EXTRA_SPECS_PREFIX = "aggregate_instance_extra_specs:"
+)
+
+// 规格对单个节点的资源需求, 由 flavor 及其 extra specs 解析得到
+type fitRequest struct {
+	Resources  map[string]int
+	Required   []string
+	Forbidden  []string
+	AggSpecs   map[string]string
+	Pinned     bool
+	PageSizeKB int
+	NumaNodes  int
+	Vcpus      int
+	RamMB      int
+}
+
+// 大页大小, 单位 KB
+func parsePageSize(value string) (int, error) {
+	switch strings.ToLower(value) {
+	case "", "small", "any":
+		// small 或 any 不要求使用大页
+		return 0, nil
+	case "large":
+		return 2048, nil
+	}
+	lower, unit := strings.ToLower(value), 1
+	for _, u := range []struct {
+		Suffix string
+		Size   int
+	}{{"kb", 1}, {"mb", 1024}, {"gb", 1024 * 1024}, {"k", 1}, {"m", 1024}, {"g", 1024 * 1024}} {
+		if strings.HasSuffix(lower, u.Suffix) {
+			lower, unit = strings.TrimSuffix(lower, u.Suffix), u.Size
+			break
+		}
+	}
+	size, err := strconv.Atoi(lower)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hw:mem_page_size %s", value)
+	}
+	return size * unit, nil
+}
+
+func parseFitRequest(flavor nova.Flavor) (*fitRequest, error) {
+	req := fitRequest{
+		Resources: map[string]int{}, AggSpecs: map[string]string{},
+		Vcpus: flavor.Vcpus, RamMB: flavor.Ram, NumaNodes: 1,
+	}
+	req.Pinned = flavor.ExtraSpecs["hw:cpu_policy"] == "dedicated"
+	if req.Pinned {
+		req.Resources["PCPU"] = flavor.Vcpus
+	} else {
+		req.Resources["VCPU"] = flavor.Vcpus
+	}
+	req.Resources["MEMORY_MB"] = flavor.Ram
+	if disk := flavor.TotalDiskGB(); disk > 0 {
+		req.Resources["DISK_GB"] = disk
+	}
+	pageSize, err := parsePageSize(flavor.ExtraSpecs["hw:mem_page_size"])
+	if err != nil {
+		return nil, err
+	}
+	req.PageSizeKB = pageSize
+	if value, ok := flavor.ExtraSpecs["hw:numa_nodes"]; ok {
+		if req.NumaNodes, err = strconv.Atoi(value); err != nil || req.NumaNodes < 1 {
+			return nil, fmt.Errorf("invalid hw:numa_nodes %s", value)
+		}
+	}
+	for key, value := range flavor.ExtraSpecs {
+		switch {
+		case strings.HasPrefix(key, "trait:"):
+			trait := strings.TrimPrefix(key, "trait:")
+			if value == "required" {
+				req.Required = append(req.Required, trait)
+			} else if value == "forbidden" {
+				req.Forbidden = append(req.Forbidden, trait)
+			}
+		case strings.HasPrefix(key, "resources:"):
+			amount, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid extra spec %s=%s", key, value)
+			}
+			rc := strings.TrimPrefix(key, "resources:")
+			if amount == 0 {
+				delete(req.Resources, rc)
+			} else {
+				req.Resources[rc] = amount
+			}
+		case strings.HasPrefix(key, AGG_EXTRA_SPECS_PREFIX):
+			req.AggSpecs[strings.TrimPrefix(key, AGG_EXTRA_SPECS_PREFIX)] = value
+		}
+	}
+	sort.Strings(req.Required)
+	sort.Strings(req.Forbidden)
+	return &req, nil
+}
+
+func (req fitRequest) NeedNuma() bool {
+	return req.Pinned || req.PageSizeKB > 0
+}
+
+// 返回 placement allocation candidates 接口的查询参数
+func (req fitRequest) PlacementQuery() url.Values {
+	resources := lo.MapToSlice(req.Resources, func(rc string, amount int) string {
+		return fmt.Sprintf("%s:%d", rc, amount)
+	})
+	sort.Strings(resources)
+	query := url.Values{"resources": []string{strings.Join(resources, ",")}}
+	traits := append([]string{}, req.Required...)
+	for _, trait := range req.Forbidden {
+		traits = append(traits, "!"+trait)
+	}
+	if len(traits) > 0 {
+		query.Set("required", strings.Join(traits, ","))
+	}
+	return query
+}
+
+type FlavorFit struct {
+	Host       string
+	Hypervisor string
+	Source     string
+	Fit        int
+	Limit      string
+}
+
+// 记录各资源可容纳的数量, 取最小值作为结果
+type fitCounter struct {
+	Fit   int
+	Limit string
+}
+
+func newFitCounter() *fitCounter {
+	return &fitCounter{Fit: math.MaxInt}
+}
+func (f *fitCounter) Add(resource string, count int) {
+	if count < 0 {
+		count = 0
+	}
+	if count < f.Fit {
+		f.Fit, f.Limit = count, resource
+	}
+}
+func (f *fitCounter) Reject(reason string) {
+	f.Fit, f.Limit = 0, reason
+}
+func (f fitCounter) Result() (int, string) {
+	if f.Fit == math.MaxInt {
+		return 0, "no resource requested"
+	}
+	return f.Fit, f.Limit
+}
+
+// 计算跨 nodes 个 NUMA 节点时可以创建的数量, slots 为各节点可容纳的分片数
+func numaFit(slots []int, nodes int) int {
+	if nodes <= 0 || len(slots) < nodes {
+		return 0
+	}
+	fit := 0
+	for k := 1; ; k++ {
+		total := lo.SumBy(slots, func(s int) int { return min(s, k) })
+		if total < k*nodes {
+			return fit
+		}
+		fit = k
+	}
+}
+
+// 根据 hypervisor 上报的 NUMA 数据计算绑核及大页的可容纳数量
+func fitNumaNodes(hypervisor nova.Hypervisor, req fitRequest, counter *fitCounter) {
+	if len(hypervisor.NumaNodes) == 0 {
+		counter.Reject("no NUMA data")
+		return
+	}
+	cpuPerNode := int(math.Ceil(float64(req.Vcpus) / float64(req.NumaNodes)))
+	pagesPerNode := 0
+	if req.PageSizeKB > 0 {
+		pagesPerNode = int(math.Ceil(float64(req.RamMB) * 1024 / float64(req.PageSizeKB) / float64(req.NumaNodes)))
+	}
+	cpuSlots, pageSlots, slots := []int{}, []int{}, []int{}
+	for _, node := range hypervisor.NumaNodes {
+		nodeSlots := math.MaxInt
+		if req.Pinned && cpuPerNode > 0 {
+			cpuSlots = append(cpuSlots, node.CpuSet.Free/cpuPerNode)
+			nodeSlots = min(nodeSlots, node.CpuSet.Free/cpuPerNode)
+		}
+		if pagesPerNode > 0 {
+			pageSlots = append(pageSlots, node.HugePages.Free/pagesPerNode)
+			nodeSlots = min(nodeSlots, node.HugePages.Free/pagesPerNode)
+		}
+		if nodeSlots != math.MaxInt {
+			slots = append(slots, nodeSlots)
+		}
+	}
+	if len(cpuSlots) > 0 {
+		counter.Add("PCPU(NUMA)", numaFit(cpuSlots, req.NumaNodes))
+	}
+	if len(pageSlots) > 0 {
+		counter.Add("HugePages(NUMA)", numaFit(pageSlots, req.NumaNodes))
+	}
+	if len(slots) > 0 {
+		counter.Add("NUMA", numaFit(slots, req.NumaNodes))
+	}
+}
+
+type fitRatios struct {
+	Cpu  float64
+	Ram  float64
+	Disk float64
+}
+
+// 不使用 placement 时, 根据 hypervisor 数据及超分比估算
+func fitHypervisor(hypervisor nova.Hypervisor, req fitRequest, ratios fitRatios, counter *fitCounter) {
+	if hypervisor.Vcpus == 0 && hypervisor.MemoryMB == 0 {
+		counter.Reject("no resource data")
+		return
+	}
+	if !req.Pinned && req.Vcpus > 0 {
+		free := float64(hypervisor.Vcpus)*ratios.Cpu - float64(hypervisor.VcpusUsed)
+		counter.Add("VCPU", int(free)/req.Vcpus)
+	}
+	if req.PageSizeKB == 0 && req.RamMB > 0 {
+		free := float64(hypervisor.MemoryMB)*ratios.Ram - float64(hypervisor.MemoryMBUsed)
+		counter.Add("MEMORY_MB", int(free)/req.RamMB)
+	}
+	if disk := req.Resources["DISK_GB"]; disk > 0 && hypervisor.LocalGb > 0 {
+		free := float64(hypervisor.LocalGb)*ratios.Disk - float64(hypervisor.LocalGbUsed)
+		counter.Add("DISK_GB", int(free)/disk)
+	}
+	// 只能校验 CPU 指令集相关的 trait
+	features := lo.Map(hypervisor.CpuInfo.Features, func(f string, _ int) string {
+		return strings.ToLower(f)
+	})
+	for _, trait := range req.Required {
+		if feature, ok := cpuTraitFeature(trait); ok && !lo.Contains(features, feature) {
+			counter.Reject("missing trait " + trait)
+			return
+		}
+	}
+	for _, trait := range req.Forbidden {
+		if feature, ok := cpuTraitFeature(trait); ok && lo.Contains(features, feature) {
+			counter.Reject("forbidden trait " + trait)
+			return
+		}
+	}
+}
+
+func cpuTraitFeature(trait string) (string, bool) {
+	if !strings.HasPrefix(trait, "HW_CPU_X86_") {
+		return "", false
+	}
+	return strings.ToLower(strings.TrimPrefix(trait, "HW_CPU_X86_")), true
+}
+
+// 使用 placement allocation candidates 计算每个资源提供者的可容纳数量
+func fitPlacement(client *openstack.Openstack, req fitRequest) (map[string]*fitCounter, error) {
+	candidates, err := client.PlacementV1().ListAllocationCandidates(req.PlacementQuery())
+	if err != nil {
+		return nil, err
+	}
+	providers, err := client.PlacementV1().ListResourceProviders(nil)
+	if err != nil {
+		return nil, err
+	}
+	names := lo.SliceToMap(providers, func(p placement.ResourceProvider) (string, string) {
+		return p.Uuid, p.Name
+	})
+	counters := map[string]*fitCounter{}
+	for _, request := range candidates.AllocationRequests {
+		for rpUuid, alloc := range request.Allocations {
+			summary := candidates.ProviderSummaries[rpUuid]
+			counter := newFitCounter()
+			for rc, amount := range alloc.Resources {
+				if amount <= 0 {
+					continue
+				}
+				r := summary.Resources[rc]
+				counter.Add(rc, (r.Capacity-r.Used)/amount)
+			}
+			name := names[rpUuid]
+			if exists, ok := counters[name]; !ok || counter.Fit > exists.Fit {
+				counters[name] = counter
+			}
+		}
+	}
+	return counters, nil
+}
+
+// 校验主机所在聚合的元数据是否满足 aggregate_instance_extra_specs
+func matchAggregateSpecs(host string, aggregates []nova.Aggregate, specs map[string]string) (bool, string) {
+	metadata := map[string][]string{}
+	for _, agg := range aggregates {
+		if !lo.Contains(agg.Hosts, host) {
+			continue
+		}
+		for k, v := range agg.Metadata {
+			for _, item := range strings.Split(v, ",") {
+				metadata[k] = append(metadata[k], strings.TrimSpace(item))
+			}
+		}
+	}
+	for key, value := range specs {
+		if !lo.Contains(metadata[key], value) {
+			return false, fmt.Sprintf("aggregate metadata %s!=%s", key, value)
+		}
+	}
+	return true, ""
+}
+
+// 获取待计算的主机, 未指定可用域和聚合时返回 nil, 表示所有主机
+func fitCandidateHosts(client *openstack.Openstack, az string, aggregate string) ([]string, error) {
+	var hosts []string
+	if az != "" {
+		azList, err := client.NovaV2().ListAZ(nil, true)
+		if err != nil {
+			return nil, err
+		}
+		zone, ok := lo.Find(azList, func(item nova.AvailabilityZone) bool { return item.ZoneName == az })
+		if !ok {
+			return nil, fmt.Errorf("availability zone %s not found", az)
+		}
+		for host, services := range zone.Hosts {
+			if _, ok := services["nova-compute"]; ok {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	if aggregate != "" {
+		agg, err := client.NovaV2().FindAgg(aggregate)
+		if err != nil {
+			return nil, err
+		}
+		if hosts == nil {
+			hosts = agg.Hosts
+		} else {
+			hosts = lo.Intersect(hosts, agg.Hosts)
+		}
+		if hosts == nil {
+			hosts = []string{}
+		}
+	}
+	return hosts, nil
+}
+
+var flavorFit = &cobra.Command{
+	Use:   "fit <flavor>",
+	Short: "Calculate how many servers of flavor can fit on each host",
+	Long: "Calculate how many servers of flavor can fit on each host.\n" +
+		"Placement allocation candidates are used when placement is available, " +
+		"otherwise the result is estimated from hypervisor data and allocation ratios. " +
+		"NUMA data of hypervisor is used for pinned CPUs and huge pages.",
+	Example: "hypervisor flavor fit m1.large\n" +
+		"hypervisor flavor fit m1.large --az nova --aggregate ssd",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		az, _ := cmd.Flags().GetString("az")
+		aggregate, _ := cmd.Flags().GetString("aggregate")
+		noPlacement, _ := cmd.Flags().GetBool("no-placement")
+		ratios := fitRatios{}
+		ratios.Cpu, _ = cmd.Flags().GetFloat64("cpu-allocation-ratio")
+		ratios.Ram, _ = cmd.Flags().GetFloat64("ram-allocation-ratio")
+		ratios.Disk, _ = cmd.Flags().GetFloat64("disk-allocation-ratio")
+
+		client := common.DefaultClient()
+		flavor, err := client.NovaV2().FindFlavor(args[0])
+		utility.LogIfError(err, true, "get flavor %s failed", args[0])
+		extraSpecs, err := client.NovaV2().GetFlavorExtraSpecs(flavor.Id)
+		utility.LogError(err, "get flavor extra specs failed", true)
+		flavor.ExtraSpecs = extraSpecs
+
+		req, err := parseFitRequest(*flavor)
+		utility.LogError(err, "parse flavor failed", true)
+
+		hosts, err := fitCandidateHosts(client, az, aggregate)
+		utility.LogError(err, "get hosts failed", true)
+		hypervisors, err := client.NovaV2().ListHypervisor(nil, true)
+		utility.LogError(err, "list hypervisors failed", true)
+		if hosts != nil {
+			hypervisors = lo.Filter(hypervisors, func(h nova.Hypervisor, _ int) bool {
+				return lo.Contains(hosts, h.Host)
+			})
+		}
+		aggregates := []nova.Aggregate{}
+		if len(req.AggSpecs) > 0 {
+			aggregates, err = client.NovaV2().ListAgg(nil)
+			utility.LogError(err, "list aggregates failed", true)
+		}
+
+		var placementFit map[string]*fitCounter
+		if !noPlacement {
+			placementFit, err = fitPlacement(client, *req)
+			if err != nil {
+				console.Warn("query allocation candidates failed, estimate from hypervisors: %s", err)
+			}
+		}
+		items := []FlavorFit{}
+		for _, hypervisor := range hypervisors {
+			item := FlavorFit{Host: hypervisor.Host, Hypervisor: hypervisor.HypervisorHostname}
+			counter := newFitCounter()
+			if hypervisor.Status == "disabled" || hypervisor.State == "down" {
+				counter.Reject(fmt.Sprintf("%s/%s", hypervisor.Status, hypervisor.State))
+			} else if ok, reason := matchAggregateSpecs(hypervisor.Host, aggregates, req.AggSpecs); !ok {
+				counter.Reject(reason)
+			} else {
+				if placementFit != nil {
+					item.Source = FIT_SOURCE_PLACEMENT
+					if c, ok := placementFit[hypervisor.HypervisorHostname]; ok {
+						counter = c
+					} else {
+						counter.Reject("no allocation candidate")
+					}
+				} else {
+					item.Source = FIT_SOURCE_HYPERVISOR
+					fitHypervisor(hypervisor, *req, ratios, counter)
+				}
+				// placement 不感知 NUMA 拓扑, 绑核和大页仍需根据 NUMA 数据计算
+				if req.NeedNuma() && counter.Fit > 0 {
+					detail, err := client.NovaV2().GetHypervisor(hypervisor.Id)
+					if err != nil {
+						console.Warn("get hypervisor %s failed: %s", hypervisor.HypervisorHostname, err)
+					} else {
+						fitNumaNodes(*detail, *req, counter)
+					}
+				}
+			}
+			item.Fit, item.Limit = counter.Result()
+			items = append(items, item)
+		}
+		common.PrintItems(
+			[]datatable.Column[FlavorFit]{
+				{Name: "Host"}, {Name: "Hypervisor"}, {Name: "Source"},
+				{Name: "Fit", Align: text.AlignRight}, {Name: "Limit"},
+			},
+			[]datatable.Column[FlavorFit]{},
+			items,
+			common.TableOptions{SortBy: []table.SortBy{{Name: "Fit", Mode: table.DscNumeric}, {Name: "Host"}}},
+		)
+		console.Info("flavor %s can fit %d server(s) on %d host(s)", flavor.Name,
+			lo.SumBy(items, func(item FlavorFit) int { return item.Fit }), len(items))
+	},
+}
+
+func init() {
+	flavorFit.Flags().String("az", "", "Only calculate hosts in availability zone")
+	flavorFit.Flags().String("aggregate", "", "Only calculate hosts in aggregate (name or ID)")
+	flavorFit.Flags().Bool("no-placement", false, "Do not use placement, estimate from hypervisor data")
+	flavorFit.Flags().Float64("cpu-allocation-ratio", 16.0, "CPU allocation ratio used without placement")
+	flavorFit.Flags().Float64("ram-allocation-ratio", 1.5, "RAM allocation ratio used without placement")
+	flavorFit.Flags().Float64("disk-allocation-ratio", 1.0, "Disk allocation ratio used without placement")
+
+	hypervisorFlavor.AddCommand(flavorFit)
+}
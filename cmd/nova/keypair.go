@@ -1,9 +1,15 @@
 package nova
 
 import (
+	"fmt"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/duke-git/lancet/v2/fileutil"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 
 	"github.com/BytemanD/go-console/console"
@@ -18,6 +24,11 @@ var (
 	keypairCreateFlags flags.KeypairCreateFlags
 )
 
+const (
+	KEYPAIR_TYPE_SSH  = "ssh"
+	KEYPAIR_TYPE_X509 = "x509"
+)
+
 var Keypair = &cobra.Command{Use: "keypair"}
 
 var keypairList = &cobra.Command{
@@ -54,28 +65,132 @@ var keypairList = &cobra.Command{
 		common.PrintPrettyTable(pt, false)
 	},
 }
+
+// 查找用于校验指纹的本地私钥
+func findLocalPrivateKey(name string) string {
+	candidates := []string{name + ".pem"}
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, keyName := range []string{"id_ed25519", "id_rsa", "id_ecdsa"} {
+			candidates = append(candidates, filepath.Join(home, ".ssh", keyName))
+		}
+	}
+	for _, file := range candidates {
+		if fileutil.IsExist(file) {
+			return file
+		}
+	}
+	return ""
+}
+
+// 校验本地私钥是否与密钥对匹配
+func verifyKeypair(keypair nova.Keypair, privateKeyFile string) error {
+	localKey, err := utility.LoadPrivateKeyPublic(privateKeyFile)
+	if err != nil {
+		return err
+	}
+	if keypair.Keypair.Type == KEYPAIR_TYPE_X509 {
+		serverKey, err := utility.ParseKeypairPublicKey(keypair.Keypair.Type, keypair.Keypair.PublicKey)
+		if err != nil {
+			return err
+		}
+		if !utility.PublicKeyEqual(localKey, serverKey) {
+			return fmt.Errorf("private key %s does not match the certificate of keypair", privateKeyFile)
+		}
+		return nil
+	}
+	publicKey, err := utility.MarshalSSHPublicKey(localKey, "")
+	if err != nil {
+		return err
+	}
+	fingerprint, err := utility.KeypairFingerprint(KEYPAIR_TYPE_SSH, publicKey)
+	if err != nil {
+		return err
+	}
+	if fingerprint != keypair.Keypair.Fingerprint {
+		return fmt.Errorf("fingerprint of private key %s is %s, expect %s",
+			privateKeyFile, fingerprint, keypair.Keypair.Fingerprint)
+	}
+	return nil
+}
+
 var keypairShow = &cobra.Command{
 	Use:   "show <name>",
 	Short: "show keypair",
-	Args:  cobra.ExactArgs(1),
-	Run: func(_ *cobra.Command, args []string) {
+	Example: "keypair show key1\n" +
+		"keypair show key1 --fingerprint --private-key ~/.ssh/id_ed25519",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fingerprint, _ := cmd.Flags().GetBool("fingerprint")
+		privateKey, _ := cmd.Flags().GetString("private-key")
+
 		client := common.DefaultClient()
 		keypair, err := client.NovaV2().GetKeypair(args[0])
 
 		utility.LogIfError(err, true, "get keypair failed")
 		common.PrintKeypair(*keypair)
+		if !fingerprint {
+			return
+		}
+		if privateKey == "" {
+			if privateKey = findLocalPrivateKey(args[0]); privateKey == "" {
+				console.Fatal("private key not found, please use --private-key")
+			}
+		}
+		if err := verifyKeypair(*keypair, privateKey); err != nil {
+			console.Fatal("verify fingerprint failed: %s", err)
+		}
+		console.Info("private key %s matches keypair %s", privateKey, args[0])
 	},
 }
+
+// 在本地生成密钥对, 私钥保存到文件中, 返回需要上传的公钥或证书
+func generateLocalKeypair(name string, keyType string, privateKeyFile string) (string, error) {
+	if fileutil.IsExist(privateKeyFile) {
+		return "", fmt.Errorf("file %s already exists", privateKeyFile)
+	}
+	privateKey, publicKey, err := utility.GenerateKeyPair(
+		*keypairCreateFlags.Algorithm, *keypairCreateFlags.Bits)
+	if err != nil {
+		return "", err
+	}
+	var content string
+	if keyType == KEYPAIR_TYPE_X509 {
+		content, err = utility.GenerateX509Certificate(privateKey, name, 3650)
+	} else {
+		content, err = utility.MarshalSSHPublicKey(publicKey, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(privateKeyFile, privateKey, 0600); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
 var keypairCreate = &cobra.Command{
 	Use:   "create <name>",
 	Short: "create keypair",
-	Args:  cobra.ExactArgs(1),
+	Long: "Create keypair.\n" +
+		"By default, the key pair is generated locally, the private key is saved to file " +
+		"and only the public key (or x509 certificate) is uploaded.",
+	Example: "keypair create key1\n" +
+		"keypair create key1 --algorithm rsa --bits 4096 --private-key ~/.ssh/key1\n" +
+		"keypair create key1 --type x509\n" +
+		"keypair create key1 --pub-key ~/.ssh/id_ed25519.pub",
+	Args: cobra.ExactArgs(1),
 	Run: func(_ *cobra.Command, args []string) {
+		keyType := *keypairCreateFlags.Type
+		if !lo.Contains([]string{KEYPAIR_TYPE_SSH, KEYPAIR_TYPE_X509}, keyType) {
+			console.Fatal("invalid type %s, it must be %s or %s", keyType, KEYPAIR_TYPE_SSH, KEYPAIR_TYPE_X509)
+		}
 		client := common.DefaultClient()
 		opt := nova.KeypairOpt{
 			UserId: *keypairCreateFlags.UserId,
 		}
-		if *keypairCreateFlags.PubKey != "" {
+		privateKeyFile := ""
+		switch {
+		case *keypairCreateFlags.PubKey != "":
 			if !fileutil.IsExist(*keypairCreateFlags.PubKey) {
 				console.Fatal("file '%s' not exists", *keypairCreateFlags.PubKey)
 			}
@@ -87,16 +202,124 @@ var keypairCreate = &cobra.Command{
 			} else {
 				utility.LogIfError(err, true, "read public key failed")
 			}
+		case *keypairCreateFlags.ServerSide:
+		default:
+			privateKeyFile = *keypairCreateFlags.PrivateKey
+			if privateKeyFile == "" {
+				privateKeyFile = args[0] + ".pem"
+			}
+			content, err := generateLocalKeypair(args[0], keyType, privateKeyFile)
+			utility.LogIfError(err, true, "generate keypair failed")
+			opt.PublicKey = content
 		}
 
-		keypair, err := client.NovaV2().CreateKeypair(
-			args[0], *keypairCreateFlags.Type, opt)
-
-		utility.LogIfError(err, true, "create keypair failed")
+		keypair, err := client.NovaV2().CreateKeypair(args[0], keyType, opt)
 		if err != nil {
+			if privateKeyFile != "" {
+				os.Remove(privateKeyFile)
+			}
 			console.Fatal("create keypair failed: %s", err)
 		}
 		common.PrintKeypair(*keypair)
+		if privateKeyFile != "" {
+			console.Info("private key saved to %s", privateKeyFile)
+		}
+	},
+}
+
+// 根据公钥注释生成密钥对名称, 名称只能包含字母、数字、空格以及 _-.@
+func keypairNameFromComment(comment string) string {
+	name := regexp.MustCompile(`[^a-zA-Z0-9 _.@-]`).ReplaceAllString(strings.TrimSpace(comment), "-")
+	return strings.Trim(name, " ")
+}
+
+type importKeypair struct {
+	Name      string
+	File      string
+	PublicKey string
+}
+
+func loadImportKeypairs(files []string, name string) ([]importKeypair, error) {
+	items := []importKeypair{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := utility.ParseAuthorizedKeys(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s failed: %w", file, err)
+		}
+		baseName := strings.TrimSuffix(filepath.Base(file), ".pub")
+		for i, key := range keys {
+			item := importKeypair{File: file, PublicKey: key.PublicKey}
+			item.Name = keypairNameFromComment(key.Comment)
+			if item.Name == "" {
+				item.Name = baseName
+				if len(keys) > 1 {
+					item.Name = fmt.Sprintf("%s-%d", baseName, i+1)
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	if name != "" {
+		for i := range items {
+			items[i].Name = name
+			if len(items) > 1 {
+				items[i].Name = fmt.Sprintf("%s-%d", name, i+1)
+			}
+		}
+	}
+	return items, nil
+}
+
+var keypairImport = &cobra.Command{
+	Use:   "import [<public key file> ...]",
+	Short: "Import public key(s) as keypair(s)",
+	Long: "Import public key(s) as keypair(s).\n" +
+		"The file can be a public key file or an authorized_keys file with multiple entries, " +
+		"the keypair name is taken from the comment of public key. " +
+		"If no file is specified, import ~/.ssh/*.pub.",
+	Example: "keypair import\n" +
+		"keypair import ~/.ssh/id_ed25519.pub --name my-key\n" +
+		"keypair import ~/.ssh/authorized_keys",
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		userId, _ := cmd.Flags().GetString("user-id")
+		if len(args) == 0 {
+			home, err := os.UserHomeDir()
+			utility.LogError(err, "get home dir failed", true)
+			args, _ = filepath.Glob(filepath.Join(home, ".ssh", "*.pub"))
+			if len(args) == 0 {
+				console.Fatal("no public key found in %s", filepath.Join(home, ".ssh"))
+			}
+		}
+		items, err := loadImportKeypairs(args, name)
+		utility.LogError(err, "load public keys failed", true)
+
+		client := common.DefaultClient()
+		query := url.Values{}
+		if userId != "" {
+			query.Set("user_id", userId)
+		}
+		existing, err := client.NovaV2().ListKeypair(query)
+		utility.LogError(err, "list keypairs failed", true)
+		existingNames := lo.Map(existing, func(k nova.Keypair, _ int) string { return k.Keypair.Name })
+		for _, item := range items {
+			if lo.Contains(existingNames, item.Name) {
+				console.Warn("keypair %s already exists, skip %s", item.Name, item.File)
+				continue
+			}
+			keypair, err := client.NovaV2().CreateKeypair(
+				item.Name, KEYPAIR_TYPE_SSH, nova.KeypairOpt{PublicKey: item.PublicKey, UserId: userId})
+			if err != nil {
+				console.Error("import keypair %s from %s failed: %s", item.Name, item.File, err)
+				continue
+			}
+			existingNames = append(existingNames, item.Name)
+			console.Info("imported keypair %s (%s) from %s", item.Name, keypair.Keypair.Fingerprint, item.File)
+		}
 	},
 }
 
@@ -119,8 +342,22 @@ func init() {
 	}
 	keypairCreateFlags = flags.KeypairCreateFlags{
 		UserId: keypairCreate.Flags().String("user-id", "", "ID of user to whom to add key-pair (Admin only)."),
-		Type:   keypairCreate.Flags().String("type", "ssh", " Keypair type. Can be ssh, ecdsa or x509."),
+		Type:   keypairCreate.Flags().String("type", KEYPAIR_TYPE_SSH, "Keypair type. Can be ssh or x509."),
 		PubKey: keypairCreate.Flags().String("pub-key", "", "Path to a public ssh key."),
+		Algorithm: keypairCreate.Flags().String("algorithm", utility.KEY_ALGORITHM_ED25519,
+			"Algorithm of the generated key, ed25519 or rsa."),
+		Bits:       keypairCreate.Flags().Int("bits", 2048, "Bit length of the generated RSA key."),
+		PrivateKey: keypairCreate.Flags().String("private-key", "", "File to save the generated private key, defaults to <name>.pem."),
+		ServerSide: keypairCreate.Flags().Bool("server-side", false, "Let server generate the keypair (requires micro version < 2.92)."),
 	}
-	Keypair.AddCommand(keypairList, keypairShow, keypairCreate, keypairDelete)
+	keypairCreate.MarkFlagsMutuallyExclusive("pub-key", "server-side")
+
+	keypairShow.Flags().Bool("fingerprint", false, "Verify that the local private key matches the keypair")
+	keypairShow.Flags().String("private-key", "",
+		"Private key file to verify, defaults to <name>.pem or ~/.ssh/id_{ed25519,rsa,ecdsa}")
+
+	keypairImport.Flags().String("name", "", "Name of keypair, a suffix is added if multiple keys are imported")
+	keypairImport.Flags().String("user-id", "", "ID of user to whom to add key-pair (Admin only).")
+
+	Keypair.AddCommand(keypairList, keypairShow, keypairCreate, keypairImport, keypairDelete)
 }
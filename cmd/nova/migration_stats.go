@@ -0,0 +1,313 @@
+package nova
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const MIGRATION_PAGE_SIZE = 1000
+
+var (
+	migrationSuccessStatus = []string{"completed", "finished", "confirmed", "done"}
+	migrationFailedStatus  = []string{"error", "failed"}
+)
+
+type MigrationStat struct {
+	Key         string  `json:"key"`
+	Total       int     `json:"total"`
+	Success     int     `json:"success"`
+	Failed      int     `json:"failed"`
+	Other       int     `json:"other"`
+	SuccessRate float64 `json:"success_rate"`
+	AvgDuration float64 `json:"avg_duration_seconds"`
+	P95Duration float64 `json:"p95_duration_seconds"`
+}
+
+type HostFailure struct {
+	Host   string `json:"host"`
+	Failed int    `json:"failed"`
+	Total  int    `json:"total"`
+}
+
+type MigrationReport struct {
+	Since           string          `json:"since,omitempty"`
+	Until           string          `json:"until,omitempty"`
+	Summary         MigrationStat   `json:"summary"`
+	ByType          []MigrationStat `json:"by_type"`
+	BySourceHost    []MigrationStat `json:"by_source_host"`
+	ByDestHost      []MigrationStat `json:"by_dest_host"`
+	ByWindow        []MigrationStat `json:"by_window"`
+	TopFailingHosts []HostFailure   `json:"top_failing_hosts"`
+}
+
+type migrationCounter struct {
+	total, success, failed int
+	durations              []time.Duration
+}
+
+func (c *migrationCounter) add(migration nova.Migration) {
+	c.total++
+	status := strings.ToLower(migration.Status)
+	switch {
+	case lo.Contains(migrationSuccessStatus, status):
+		c.success++
+		createdAt, err1 := utility.ParseUTCTime(migration.CreatedAt)
+		updatedAt, err2 := utility.ParseUTCTime(migration.UpdatedAt)
+		if err1 == nil && err2 == nil && !updatedAt.Before(createdAt) {
+			c.durations = append(c.durations, updatedAt.Sub(createdAt))
+		}
+	case lo.Contains(migrationFailedStatus, status):
+		c.failed++
+	}
+}
+
+func (c migrationCounter) stat(key string) MigrationStat {
+	stat := MigrationStat{
+		Key: key, Total: c.total, Success: c.success, Failed: c.failed,
+		Other: c.total - c.success - c.failed,
+	}
+	if c.success+c.failed > 0 {
+		stat.SuccessRate = float64(c.success) / float64(c.success+c.failed)
+	}
+	if len(c.durations) > 0 {
+		durations := slices.Clone(c.durations)
+		slices.Sort(durations)
+		stat.AvgDuration = (lo.Sum(durations) / time.Duration(len(durations))).Seconds()
+		p95 := int(math.Ceil(float64(len(durations))*0.95)) - 1
+		stat.P95Duration = durations[max(p95, 0)].Seconds()
+	}
+	return stat
+}
+
+type migrationGroup map[string]*migrationCounter
+
+func (g migrationGroup) add(key string, migration nova.Migration) {
+	if key == "" {
+		key = "-"
+	}
+	if _, ok := g[key]; !ok {
+		g[key] = &migrationCounter{}
+	}
+	g[key].add(migration)
+}
+
+// 按总数降序排列, 总数相同时按名称排列
+func (g migrationGroup) stats() []MigrationStat {
+	stats := lo.MapToSlice(g, func(key string, c *migrationCounter) MigrationStat { return c.stat(key) })
+	slices.SortFunc(stats, func(a, b MigrationStat) int {
+		if a.Total != b.Total {
+			return b.Total - a.Total
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return stats
+}
+
+// 支持日期、时间以及相对当前的时长, 例如: 2024-01-01, 2024-01-01T08:00:00, 168h
+func parseTimeOrDuration(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04:05", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time or duration: %s", value)
+}
+
+func statMigrations(migrations []nova.Migration, window time.Duration, top int) MigrationReport {
+	summary := migrationCounter{}
+	byType, bySource, byDest, byWindow := migrationGroup{}, migrationGroup{}, migrationGroup{}, migrationGroup{}
+	hostFailures := map[string]*HostFailure{}
+	countHost := func(host string, failed bool) {
+		if host == "" {
+			return
+		}
+		if _, ok := hostFailures[host]; !ok {
+			hostFailures[host] = &HostFailure{Host: host}
+		}
+		hostFailures[host].Total++
+		if failed {
+			hostFailures[host].Failed++
+		}
+	}
+	for _, migration := range migrations {
+		summary.add(migration)
+		byType.add(migration.MigrationType, migration)
+		bySource.add(migration.SourceCompute, migration)
+		byDest.add(migration.DestCompute, migration)
+		if createdAt, err := utility.ParseUTCTime(migration.CreatedAt); err == nil {
+			byWindow.add(createdAt.Truncate(window).Format("2006-01-02 15:04"), migration)
+		}
+		failed := lo.Contains(migrationFailedStatus, strings.ToLower(migration.Status))
+		countHost(migration.SourceCompute, failed)
+		if migration.DestCompute != migration.SourceCompute {
+			countHost(migration.DestCompute, failed)
+		}
+	}
+	windows := byWindow.stats()
+	slices.SortFunc(windows, func(a, b MigrationStat) int { return strings.Compare(a.Key, b.Key) })
+
+	failures := lo.Filter(
+		lo.MapToSlice(hostFailures, func(_ string, f *HostFailure) HostFailure { return *f }),
+		func(f HostFailure, _ int) bool { return f.Failed > 0 },
+	)
+	slices.SortFunc(failures, func(a, b HostFailure) int {
+		if a.Failed != b.Failed {
+			return b.Failed - a.Failed
+		}
+		return strings.Compare(a.Host, b.Host)
+	})
+	return MigrationReport{
+		Summary:         summary.stat("total"),
+		ByType:          byType.stats(),
+		BySourceHost:    bySource.stats(),
+		ByDestHost:      byDest.stats(),
+		ByWindow:        windows,
+		TopFailingHosts: failures[:min(max(top, 0), len(failures))],
+	}
+}
+
+func migrationStatColumns(keyTitle string) []datatable.Column[MigrationStat] {
+	return []datatable.Column[MigrationStat]{
+		{Name: "Key", Text: keyTitle}, {Name: "Total"}, {Name: "Success"}, {Name: "Failed"}, {Name: "Other"},
+		{Name: "SuccessRate", RenderFunc: func(item MigrationStat) any {
+			if item.Success+item.Failed == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f%%", item.SuccessRate*100)
+		}},
+		{Name: "AvgDuration", RenderFunc: func(item MigrationStat) any {
+			return time.Duration(item.AvgDuration * float64(time.Second)).Round(time.Second).String()
+		}},
+		{Name: "P95Duration", RenderFunc: func(item MigrationStat) any {
+			return time.Duration(item.P95Duration * float64(time.Second)).Round(time.Second).String()
+		}},
+	}
+}
+
+func printMigrationReport(report MigrationReport) {
+	switch common.CONF.Format {
+	case common.JSON:
+		fmt.Println(common.MarshalModel(report, true))
+		return
+	case common.YAML:
+		output, err := common.GetYaml(report)
+		utility.LogError(err, "get yaml failed", true)
+		fmt.Println(output)
+		return
+	}
+	sections := []struct {
+		title    string
+		keyTitle string
+		stats    []MigrationStat
+	}{
+		{"Summary", "", []MigrationStat{report.Summary}},
+		{"By type", "Type", report.ByType},
+		{"By source host", "Source Host", report.BySourceHost},
+		{"By dest host", "Dest Host", report.ByDestHost},
+		{"By window", "Window", report.ByWindow},
+	}
+	for _, section := range sections {
+		fmt.Printf("%s:\n", section.title)
+		common.PrintItems(migrationStatColumns(section.keyTitle), nil, section.stats, common.TableOptions{})
+	}
+	fmt.Println("Top failing hosts:")
+	common.PrintItems(
+		[]datatable.Column[HostFailure]{{Name: "Host"}, {Name: "Failed"}, {Name: "Total"}},
+		nil, report.TopFailingHosts, common.TableOptions{},
+	)
+}
+
+var migrationStats = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics of migrations",
+	Long: "Show success/failure rate and duration of migrations, grouped by type, source host,\n" +
+		"dest host and time window. Duration is calculated from created_at and updated_at\n" +
+		"of successful migrations.",
+	Example: "migration stats --since 168h\n" +
+		"migration stats --since 2024-01-01 --until 2024-02-01 --type live-migration\n" +
+		"migration stats --since 168h --window 1h -f json",
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		since, _ := cmd.Flags().GetString("since")
+		until, _ := cmd.Flags().GetString("until")
+		host, _ := cmd.Flags().GetString("host")
+		migrationType, _ := cmd.Flags().GetString("type")
+		window, _ := cmd.Flags().GetDuration("window")
+		top, _ := cmd.Flags().GetInt("top")
+		if window <= 0 {
+			console.Error("window must be greater than 0")
+			os.Exit(1)
+		}
+
+		now := time.Now().UTC()
+		query := url.Values{}
+		var sinceTime, untilTime time.Time
+		if since != "" {
+			var err error
+			sinceTime, err = parseTimeOrDuration(since, now)
+			utility.LogError(err, "invalid --since", true)
+			// changes-since 过滤的是 updated_at, 结果包含所有 since 之后创建的记录
+			query.Set("changes-since", sinceTime.Format(time.RFC3339))
+		}
+		if until != "" {
+			var err error
+			untilTime, err = parseTimeOrDuration(until, now)
+			utility.LogError(err, "invalid --until", true)
+		}
+		if host != "" {
+			query.Set("host", host)
+		}
+		if migrationType != "" {
+			query.Set("migration_type", migrationType)
+		}
+
+		client := common.DefaultClient()
+		migrations, err := client.NovaV2().ListAllMigrations(query, MIGRATION_PAGE_SIZE)
+		utility.LogError(err, "list migrations failed", true)
+
+		migrations = lo.Filter(migrations, func(m nova.Migration, _ int) bool {
+			createdAt, err := utility.ParseUTCTime(m.CreatedAt)
+			if err != nil {
+				return false
+			}
+			return (sinceTime.IsZero() || !createdAt.Before(sinceTime)) &&
+				(untilTime.IsZero() || createdAt.Before(untilTime))
+		})
+		report := statMigrations(migrations, window, top)
+		if !sinceTime.IsZero() {
+			report.Since = sinceTime.Format(time.RFC3339)
+		}
+		if !untilTime.IsZero() {
+			report.Until = untilTime.Format(time.RFC3339)
+		}
+		printMigrationReport(report)
+	},
+}
+
+func init() {
+	migrationStats.Flags().String("since", "", "Stat migrations created since this UTC time or duration ago, e.g. 2024-01-01 or 168h")
+	migrationStats.Flags().String("until", "", "Stat migrations created before this UTC time or duration ago, e.g. 2024-02-01 or 24h")
+	migrationStats.Flags().String("host", "", "Stat migrations matched by host")
+	migrationStats.Flags().String("type", "", "Stat migrations matched by migration type")
+	migrationStats.Flags().Duration("window", time.Hour*24, "Time window of trend")
+	migrationStats.Flags().Int("top", 5, "Number of top failing hosts")
+
+	Migration.AddCommand(migrationStats)
+}
@@ -55,7 +55,6 @@ func refreshServers(c *openstack.Openstack, filterHosts []string, host, az strin
 var (
 	listFlags                flags.ServerListFlags
 	setFlags                 flags.ServerSetFlags
-	deleteFlags              flags.ServerDeleteFlags
 	createFlags              flags.ServerCreateFlags
 	rebootFlags              flags.ServerRebootFlags
 	resizeFlags              flags.ServerResizeFlags
@@ -313,8 +312,10 @@ var serverCreate = &cobra.Command{
 		server, err = client.NovaV2().GetServer(server.Id)
 		utility.LogError(err, "get server failed", true)
 		views.PrintServer(*server, nil)
-		if *createFlags.Wait {
-			_, err := client.NovaV2().WaitServerStatus(server.Id, "ACTIVE", 5)
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
+			_, err := client.NovaV2().ServerWaiter(server.Id).
+				Until(func(s *nova.Server) bool { return s.IsActive() }).
+				WithTimeout(timeout).Wait()
 			if err != nil {
 				console.Error("Server %s create failed, %v", server.Id, err)
 			} else {
@@ -347,9 +348,11 @@ var serverDelete = &cobra.Command{
 			fmt.Printf("Requested to delete server: %s\n", idOrName)
 			deleteServers = append(deleteServers, s.Id)
 		}
-		if *deleteFlags.Wait {
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
 			for _, id := range deleteServers {
-				client.NovaV2().WaitServerDeleted(id)
+				_, err := client.NovaV2().ServerWaiter(id).FailWhen(nil).UntilDeleted().
+					WithTimeout(timeout).Wait()
+				utility.LogIfError(err, false, "wait server %s deleted failed", id)
 			}
 		}
 	},
@@ -420,11 +423,14 @@ var serverReboot = &cobra.Command{
 				fmt.Printf("Requested to reboot server: %s\n", server.Id)
 			}
 		}
-		if !*rebootFlags.Wait {
+		wait, timeout := common.GetWaitFlags(cmd)
+		if !wait {
 			return
 		}
 		for _, server := range servers {
-			_, err := client.NovaV2().WaitServerStatus(server.Id, "ACTIVE", 5)
+			_, err := client.NovaV2().ServerWaiter(server.Id).
+				Until(func(s *nova.Server) bool { return s.IsActive() && s.TaskState == "" }).
+				WithTimeout(timeout).Wait()
 			if err == nil {
 				console.Info("[%s] rebooted", server.Id)
 			} else {
@@ -609,9 +615,13 @@ var serverResize = &cobra.Command{
 			}
 		}
 
-		if flavor != nil && *resizeFlags.Wait {
+		if wait, timeout := common.GetWaitFlags(cmd); flavor != nil && wait {
 			for _, server := range servers {
-				_, err := client.NovaV2().WaitServerResized(server.Id, flavor.Name)
+				_, err := client.NovaV2().ServerWaiter(server.Id).
+					Until(func(s *nova.Server) bool {
+						return s.TaskState == "" && s.Flavor.OriginalName == flavor.Name
+					}).
+					WithTimeout(timeout).Wait()
 				if err != nil {
 					utility.LogIfError(err, false, "server %s resize failed: %s", server.Id, err)
 				} else {
@@ -647,9 +657,11 @@ var serverMigrate = &cobra.Command{
 				console.Info("[%s] requested to migrate server", server.Id)
 			}
 		}
-		if *migrateFlags.Wait {
+		if wait, timeout := common.GetWaitFlags(cmd); wait {
 			for _, server := range servers {
-				server, err := client.NovaV2().WaitServerTask(server.Id, "")
+				server, err := client.NovaV2().ServerWaiter(server.Id).
+					Until(func(s *nova.Server) bool { return s.TaskState == "" }).
+					WithTimeout(timeout).Wait()
 				if err != nil {
 					console.Error("[%s] migrate failed: %s", server.Id, err)
 					continue
@@ -964,32 +976,25 @@ func init() {
 		UserData:   serverCreate.Flags().String("user-data", "", "user data file to pass to be exposed by the metadata server."),
 		KeyName:    serverCreate.Flags().String("key-name", "", "Keypair to inject into this server."),
 		AdminPass:  serverCreate.Flags().String("admin-pass", "", "Admin password for the instance."),
-		Wait:       serverCreate.Flags().BoolP("wait", "w", false, "Wait server created"),
 	}
 
 	serverCreate.MarkFlagRequired("flavor")
 	serverCreate.MarkFlagRequired("image")
 
-	deleteFlags = flags.ServerDeleteFlags{
-		Wait: serverDelete.Flags().BoolP("wait", "w", false, "Wait server rebooted"),
-	}
 	rebootFlags = flags.ServerRebootFlags{
 		Hard: serverReboot.Flags().Bool("hard", false, "Perform a hard reboot"),
-		Wait: serverReboot.Flags().BoolP("wait", "w", false, "Wait server rebooted"),
 	}
 
 	migrateFlags = flags.ServerMigrateFlags{
 		Live:         serverMigrate.Flags().Bool("live", false, "Migrate running server."),
 		Host:         serverMigrate.Flags().String("host", "", "Destination host name."),
 		BlockMigrate: serverMigrate.Flags().Bool("block-migrate", false, "True in case of block_migration."),
-		Wait:         serverMigrate.Flags().Bool("wait", false, "Wait server migrated"),
 	}
 
 	resizeFlags = flags.ServerResizeFlags{
 		Flavor:  serverResize.Flags().String("flavor", "", "Resize server to specified flavor"),
 		Confirm: serverResize.Flags().Bool("confirm", false, "Confirm server resize is complete"),
 		Revert:  serverResize.Flags().Bool("revert", false, "Restore server state before resize"),
-		Wait:    serverResize.Flags().BoolP("wait", "w", false, "Wait server resize completed"),
 	}
 
 	evacuateFlags = flags.ServerEvacuateFlags{
@@ -1037,6 +1042,8 @@ func init() {
 
 	serverImageCmd.AddCommand(createImageCmd)
 
+	common.RegistryWaitFlags(serverCreate, serverDelete, serverReboot, serverMigrate, serverResize)
+
 	Server.AddCommand(
 		serverList, serverShow, serverCreate, serverDelete,
 		serverSet, serverStop, serverStart, serverReboot,
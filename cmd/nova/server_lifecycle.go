@@ -0,0 +1,217 @@
+package nova
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/utility"
+)
+
+// 对多个虚拟机依次执行操作, 单个虚拟机失败不影响其他虚拟机
+func doServersAction(action string, args []string, fn func(c *openstack.Openstack, server *nova.Server) error) {
+	client := common.DefaultClient()
+	for _, idOrName := range args {
+		server, err := client.NovaV2().FindServer(idOrName)
+		if err != nil {
+			console.Error("get server %s failed, %v", idOrName, err)
+			continue
+		}
+		if err := fn(client, server); err != nil {
+			console.Error("Reqeust to %s server %s failed, %v", action, idOrName, err)
+		} else {
+			console.Info("Requested to %s server: %s", action, idOrName)
+		}
+	}
+}
+
+var serverLock = &cobra.Command{
+	Use:   "lock <server> [<server> ...]",
+	Short: "Lock server(s)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		reason, _ := cmd.Flags().GetString("reason")
+		doServersAction("lock", args, func(c *openstack.Openstack, server *nova.Server) error {
+			return c.NovaV2().LockServer(server.Id, reason)
+		})
+	},
+}
+var serverUnlock = &cobra.Command{
+	Use:   "unlock <server> [<server> ...]",
+	Short: "Unlock server(s)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		doServersAction("unlock", args, func(c *openstack.Openstack, server *nova.Server) error {
+			return c.NovaV2().UnlockServer(server.Id)
+		})
+	},
+}
+var serverRescue = &cobra.Command{
+	Use:   "rescue <server> [<server> ...]",
+	Short: "Put server(s) in rescue mode",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		image, _ := cmd.Flags().GetString("image")
+		password, _ := cmd.Flags().GetString("password")
+		imageId := ""
+		if image != "" {
+			img, err := common.DefaultClient().GlanceV2().FindImage(image)
+			if err != nil {
+				console.Fatal("get image %s failed, %v", image, err)
+			}
+			imageId = img.Id
+		}
+		doServersAction("rescue", args, func(c *openstack.Openstack, server *nova.Server) error {
+			adminPass, err := c.NovaV2().RescueServer(server.Id, imageId, password)
+			if err == nil && adminPass != "" {
+				console.Info("[%s] admin password: %s", server.Id, adminPass)
+			}
+			return err
+		})
+	},
+}
+var serverUnrescue = &cobra.Command{
+	Use:   "unrescue <server> [<server> ...]",
+	Short: "Restore server(s) from rescue mode",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		doServersAction("unrescue", args, func(c *openstack.Openstack, server *nova.Server) error {
+			return c.NovaV2().UnrescueServer(server.Id)
+		})
+	},
+}
+var serverBackup = &cobra.Command{
+	Use:   "backup <server> [<server> ...]",
+	Short: "Create backup image of server(s)",
+	Example: "server backup vm1 --type daily --rotation 7\n" +
+		"server backup vm1 vm2 --name weekly-backup --type weekly --rotation 4",
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		backupType, _ := cmd.Flags().GetString("type")
+		rotation, _ := cmd.Flags().GetInt("rotation")
+		doServersAction("backup", args, func(c *openstack.Openstack, server *nova.Server) error {
+			backupName := fmt.Sprintf("%s-%s", server.Name, time.Now().Format("20060102150405"))
+			if name != "" {
+				backupName = name
+				if len(args) > 1 {
+					backupName = fmt.Sprintf("%s-%s", name, server.Name)
+				}
+			}
+			imageId, err := c.NovaV2().CreateServerBackup(server.Id, backupName, backupType, rotation)
+			if err == nil {
+				console.Info("[%s] backup image: %s (%s)", server.Id, backupName, imageId)
+			}
+			return err
+		})
+	},
+}
+var serverCrashDump = &cobra.Command{
+	Use:   "crash-dump <server> [<server> ...]",
+	Short: "Trigger crash dump in server(s)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		doServersAction("trigger crash dump", args, func(c *openstack.Openstack, server *nova.Server) error {
+			return c.NovaV2().TriggerCrashDump(server.Id)
+		})
+	},
+}
+
+type ServerPassword struct {
+	Id       string
+	Name     string
+	Password string
+}
+
+var serverPassword = &cobra.Command{
+	Use:   "password <server> [<server> ...]",
+	Short: "Show password of server(s)",
+	Long:  "Show password of server(s), the password is decrypted if private key is provided",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		privateKey, _ := cmd.Flags().GetString("private-key")
+		client := common.DefaultClient()
+		items := []ServerPassword{}
+		for _, idOrName := range args {
+			server, err := client.NovaV2().FindServer(idOrName)
+			if err != nil {
+				console.Error("get server %s failed, %v", idOrName, err)
+				continue
+			}
+			password, err := client.NovaV2().GetServerPassword(server.Id)
+			if err != nil {
+				console.Error("get password of server %s failed, %v", idOrName, err)
+				continue
+			}
+			if password != "" && privateKey != "" {
+				if password, err = utility.DecryptServerPassword(password, privateKey); err != nil {
+					console.Error("[%s] %v", server.Id, err)
+					continue
+				}
+			}
+			items = append(items, ServerPassword{Id: server.Id, Name: server.Name, Password: password})
+		}
+		common.PrintItems(
+			[]datatable.Column[ServerPassword]{
+				{Name: "Id"}, {Name: "Name"}, {Name: "Password"},
+			},
+			[]datatable.Column[ServerPassword]{},
+			items, common.TableOptions{},
+		)
+	},
+}
+var serverRestore = &cobra.Command{
+	Use:   "restore <server> [<server> ...]",
+	Short: "Restore soft-deleted server(s)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		doServersAction("restore", args, func(c *openstack.Openstack, server *nova.Server) error {
+			return c.NovaV2().RestoreServer(server.Id)
+		})
+	},
+}
+var serverForceDelete = &cobra.Command{
+	Use:   "force-delete <server> [<server> ...]",
+	Short: "Force delete server(s), including soft-deleted server(s)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		doServersAction("force delete", args, func(c *openstack.Openstack, server *nova.Server) error {
+			return c.NovaV2().ForceDeleteServer(server.Id)
+		})
+	},
+}
+var serverShelveOffload = &cobra.Command{
+	Use:   "shelve-offload <server> [<server> ...]",
+	Short: "Remove shelved server(s) from compute host",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		doServersAction("shelve offload", args, func(c *openstack.Openstack, server *nova.Server) error {
+			return c.NovaV2().ShelveOffloadServer(server.Id)
+		})
+	},
+}
+
+func init() {
+	serverLock.Flags().String("reason", "", "Reason for locking the server (requires micro version >= 2.73)")
+
+	serverRescue.Flags().String("image", "", "Image (name or ID) to use for rescue")
+	serverRescue.Flags().String("password", "", "Admin password of the rescued server")
+
+	serverBackup.Flags().String("name", "", "Name of the backup image, defaults to <server name>-<timestamp>")
+	serverBackup.Flags().String("type", "daily", "Backup type, e.g. daily or weekly")
+	serverBackup.Flags().Int("rotation", 1, "Number of backups to keep")
+
+	serverPassword.Flags().String("private-key", "", "Private key file used to decrypt the password")
+
+	Server.AddCommand(
+		serverLock, serverUnlock, serverRescue, serverUnrescue,
+		serverBackup, serverCrashDump, serverPassword,
+		serverRestore, serverForceDelete, serverShelveOffload,
+	)
+}
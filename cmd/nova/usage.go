@@ -0,0 +1,226 @@
+package nova
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const USAGE_TIME_FORMAT = "2006-01-02T15:04:05"
+
+var Usage = &cobra.Command{Use: "usage", Short: "Project usage from os-simple-tenant-usage"}
+
+type ProjectUsage struct {
+	ProjectId   string
+	ProjectName string
+	Servers     int
+	VcpuHours   float64
+	RamMBHours  float64
+	DiskGBHours float64
+	TotalHours  float64
+}
+
+func newProjectUsage(usage nova.TenantUsage, projectName string) ProjectUsage {
+	return ProjectUsage{
+		ProjectId:   usage.TenantId,
+		ProjectName: projectName,
+		Servers:     len(usage.ServerUsages),
+		VcpuHours:   usage.TotalVcpusUsage,
+		RamMBHours:  usage.TotalMemoryMBUsage,
+		DiskGBHours: usage.TotalLocalGBUsage,
+		TotalHours:  usage.TotalHours,
+	}
+}
+
+// 支持 2006-01-02 和 2006-01-02T15:04:05 两种格式, 与 nova 一致按 UTC 时间解析
+func parseUsageTime(value string) (time.Time, error) {
+	for _, layout := range []string{USAGE_TIME_FORMAT, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %s, it must be format: 2006-01-02 or %s",
+		value, USAGE_TIME_FORMAT)
+}
+
+// 默认查询本月的用量
+func getUsageQuery(cmd *cobra.Command) url.Values {
+	startValue, _ := cmd.Flags().GetString("start")
+	endValue, _ := cmd.Flags().GetString("end")
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := now
+	var err error
+	if startValue != "" {
+		start, err = parseUsageTime(startValue)
+		utility.LogError(err, "invalid --start", true)
+	}
+	if endValue != "" {
+		end, err = parseUsageTime(endValue)
+		utility.LogError(err, "invalid --end", true)
+	}
+	if !end.After(start) {
+		console.Fatal("--end must be later than --start")
+	}
+	return url.Values{
+		"start":    []string{start.UTC().Format(USAGE_TIME_FORMAT)},
+		"end":      []string{end.UTC().Format(USAGE_TIME_FORMAT)},
+		"detailed": []string{"1"},
+	}
+}
+
+func getProjectNames(client *openstack.Openstack) map[string]string {
+	projects, err := client.KeystoneV3().ListProject(nil)
+	if err != nil {
+		console.Warn("list projects failed: %s", err)
+		return map[string]string{}
+	}
+	return lo.SliceToMap(projects, func(p model.Project) (string, string) {
+		return p.Id, p.Name
+	})
+}
+
+func printUsagesCSV(items []ProjectUsage) {
+	writer := csv.NewWriter(os.Stdout)
+	writer.Write([]string{
+		"ProjectId", "ProjectName", "Servers",
+		"VcpuHours", "RamMBHours", "DiskGBHours", "TotalHours",
+	})
+	for _, item := range items {
+		writer.Write([]string{
+			item.ProjectId, item.ProjectName, fmt.Sprintf("%d", item.Servers),
+			fmt.Sprintf("%.2f", item.VcpuHours), fmt.Sprintf("%.2f", item.RamMBHours),
+			fmt.Sprintf("%.2f", item.DiskGBHours), fmt.Sprintf("%.2f", item.TotalHours),
+		})
+	}
+	writer.Flush()
+	utility.LogError(writer.Error(), "write csv failed", true)
+}
+
+func printUsages(items []ProjectUsage, asCSV bool) {
+	if asCSV {
+		printUsagesCSV(items)
+		return
+	}
+	renderHours := func(value float64) any { return fmt.Sprintf("%.2f", value) }
+	common.PrintItems(
+		[]datatable.Column[ProjectUsage]{
+			{Name: "ProjectId"}, {Name: "ProjectName"},
+			{Name: "Servers", Align: text.AlignRight},
+			{Name: "VcpuHours", Text: "vCPU Hours", Align: text.AlignRight,
+				RenderFunc: func(item ProjectUsage) any { return renderHours(item.VcpuHours) }},
+			{Name: "RamMBHours", Text: "RAM MB-Hours", Align: text.AlignRight,
+				RenderFunc: func(item ProjectUsage) any { return renderHours(item.RamMBHours) }},
+			{Name: "DiskGBHours", Text: "Disk GB-Hours", Align: text.AlignRight,
+				RenderFunc: func(item ProjectUsage) any { return renderHours(item.DiskGBHours) }},
+		},
+		[]datatable.Column[ProjectUsage]{
+			{Name: "TotalHours", Align: text.AlignRight,
+				RenderFunc: func(item ProjectUsage) any { return renderHours(item.TotalHours) }},
+		},
+		items,
+		common.TableOptions{
+			More:   true,
+			SortBy: []table.SortBy{{Name: "ProjectName"}},
+		},
+	)
+}
+
+var usageList = &cobra.Command{
+	Use:   "list",
+	Short: "List usage of all projects",
+	Example: "usage list\n" +
+		"usage list --start 2024-01-01 --end 2024-02-01 --csv",
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		asCSV, _ := cmd.Flags().GetBool("csv")
+		query := getUsageQuery(cmd)
+
+		client := common.DefaultClient()
+		usages, err := client.NovaV2().ListTenantUsage(query)
+		utility.LogError(err, "list usage failed", true)
+
+		projectNames := getProjectNames(client)
+		items := lo.Map(usages, func(usage nova.TenantUsage, _ int) ProjectUsage {
+			return newProjectUsage(usage, projectNames[usage.TenantId])
+		})
+		printUsages(items, asCSV)
+	},
+}
+
+var usageShow = &cobra.Command{
+	Use:   "show",
+	Short: "Show usage of project",
+	Example: "usage show\n" +
+		"usage show --project demo --start 2024-01-01 --end 2024-02-01 --long",
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		project, _ := cmd.Flags().GetString("project")
+		asCSV, _ := cmd.Flags().GetBool("csv")
+		long, _ := cmd.Flags().GetBool("long")
+		query := getUsageQuery(cmd)
+
+		client := common.DefaultClient()
+		var projectId, projectName string
+		if project == "" {
+			id, err := client.ProjectId()
+			utility.LogError(err, "get project id failed", true)
+			projectId = id
+		} else {
+			p, err := client.KeystoneV3().FindProject(project)
+			utility.LogError(err, "get project failed", true)
+			projectId, projectName = p.Id, p.Name
+		}
+		usage, err := client.NovaV2().GetTenantUsage(projectId, query)
+		utility.LogError(err, "show usage failed", true)
+
+		printUsages([]ProjectUsage{newProjectUsage(*usage, projectName)}, asCSV)
+		if !long || asCSV {
+			return
+		}
+		common.PrintItems(
+			[]datatable.Column[nova.ServerUsage]{
+				{Name: "InstanceId"}, {Name: "Name"}, {Name: "Flavor"},
+				{Name: "State", AutoColor: true},
+				{Name: "Vcpus", Align: text.AlignRight},
+				{Name: "MemoryMB", Align: text.AlignRight},
+				{Name: "LocalGB", Align: text.AlignRight},
+				{Name: "Hours", Align: text.AlignRight, RenderFunc: func(item nova.ServerUsage) any {
+					return fmt.Sprintf("%.2f", item.Hours)
+				}},
+				{Name: "StartedAt"}, {Name: "EndedAt"},
+			},
+			[]datatable.Column[nova.ServerUsage]{},
+			usage.ServerUsages,
+			common.TableOptions{},
+		)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{usageList, usageShow} {
+		cmd.Flags().String("start", "", "Usage range start date in UTC, e.g. 2024-01-01 (default: first day of this month)")
+		cmd.Flags().String("end", "", "Usage range end date in UTC, e.g. 2024-02-01 (default: now)")
+		cmd.Flags().Bool("csv", false, "Output usage as CSV")
+	}
+	usageShow.Flags().String("project", "", "Project name or id, defaults to current project")
+	usageShow.Flags().BoolP("long", "l", false, "List server usages")
+
+	Usage.AddCommand(usageList, usageShow)
+}
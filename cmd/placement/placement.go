@@ -0,0 +1,304 @@
+package placement
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack/model/placement"
+	"github.com/BytemanD/skyman/utility"
+)
+
+var PlacementCmd = &cobra.Command{Use: "placement", Short: "Placement resource providers and allocations"}
+
+var provider = &cobra.Command{Use: "provider", Short: "Resource providers"}
+var allocation = &cobra.Command{Use: "allocation", Short: "Allocations"}
+
+// 分配记录中的一项资源
+type AllocationItem struct {
+	Provider      string
+	ProviderName  string
+	Consumer      string
+	ResourceClass string
+	Amount        int
+}
+
+// 将分配记录展开, 按资源类型逐行显示
+func flattenAllocations(allocations map[string]placement.Allocation, getKeys func(key string) (string, string)) []AllocationItem {
+	items := []AllocationItem{}
+	for key, alloc := range allocations {
+		provider, consumer := getKeys(key)
+		for rc, amount := range alloc.Resources {
+			items = append(items, AllocationItem{
+				Provider: provider, Consumer: consumer,
+				ResourceClass: rc, Amount: amount,
+			})
+		}
+	}
+	return items
+}
+
+func PrintProviderResources(items []placement.ProviderResource) {
+	common.PrintItems(
+		[]datatable.Column[placement.ProviderResource]{
+			{Name: "ResourceClass"},
+			{Name: "Total", Align: text.AlignRight},
+			{Name: "Reserved", Align: text.AlignRight},
+			{Name: "AllocationRatio", Align: text.AlignRight},
+			{Name: "Capacity", Align: text.AlignRight, RenderFunc: func(item placement.ProviderResource) any {
+				return item.Capacity()
+			}},
+			{Name: "Used", Align: text.AlignRight},
+			{Name: "Free", Align: text.AlignRight, RenderFunc: func(item placement.ProviderResource) any {
+				return item.Free()
+			}},
+		},
+		[]datatable.Column[placement.ProviderResource]{
+			{Name: "MinUnit"}, {Name: "MaxUnit"}, {Name: "StepSize"},
+		},
+		items,
+		common.TableOptions{More: true, SortBy: []table.SortBy{{Name: "ResourceClass"}}},
+	)
+}
+
+const resourceUsage = "Resource class and amount, format: <resource-class>:<amount>, e.g. VCPU:2 " +
+	"(repeat option to set multiple resources)"
+
+// 将 RC:N 或者 RC=N 格式的参数转换为 placement 查询参数 resources=RC1:N1,RC2:N2
+func parseResources(values []string) (string, error) {
+	resources := []string{}
+	for _, value := range values {
+		rc, amount, ok := strings.Cut(strings.Replace(value, "=", ":", 1), ":")
+		if !ok || rc == "" {
+			return "", fmt.Errorf("invalid resource '%s', format: <resource-class>:<amount>", value)
+		}
+		if n, err := strconv.Atoi(amount); err != nil || n <= 0 {
+			return "", fmt.Errorf("invalid amount of resource '%s', it must be a positive integer", value)
+		}
+		resources = append(resources, fmt.Sprintf("%s:%s", strings.ToUpper(rc), amount))
+	}
+	return strings.Join(resources, ","), nil
+}
+
+var providerList = &cobra.Command{
+	Use:   "list",
+	Short: "List resource providers",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		name, _ := cmd.Flags().GetString("name")
+		resources, _ := cmd.Flags().GetStringArray("resource")
+		long, _ := cmd.Flags().GetBool("long")
+
+		query := url.Values{}
+		if name != "" {
+			query.Set("name", name)
+		}
+		if len(resources) > 0 {
+			value, err := parseResources(resources)
+			utility.LogError(err, "invalid resource", true)
+			query.Set("resources", value)
+		}
+		client := common.DefaultClient()
+		providers, err := client.PlacementV1().ListResourceProviders(query)
+		utility.LogError(err, "list resource providers failed", true)
+		common.PrintItems(
+			[]datatable.Column[placement.ResourceProvider]{
+				{Name: "Uuid"}, {Name: "Name"}, {Name: "Generation"},
+			},
+			[]datatable.Column[placement.ResourceProvider]{
+				{Name: "RootProviderUuid"}, {Name: "ParentProviderUuid"},
+			},
+			providers,
+			common.TableOptions{More: long, SortBy: []table.SortBy{{Name: "Name"}}},
+		)
+	},
+}
+
+var providerShow = &cobra.Command{
+	Use:   "show <provider>",
+	Short: "Show resource provider inventories, usages, traits and aggregates",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := common.DefaultClient()
+		rp, err := client.PlacementV1().FindResourceProvider(args[0])
+		utility.LogIfError(err, true, "get resource provider %s failed", args[0])
+
+		traits, err := client.PlacementV1().GetTraits(rp.Uuid)
+		utility.LogError(err, "get traits failed", true)
+		aggregates, err := client.PlacementV1().GetAggregates(rp.Uuid)
+		utility.LogError(err, "get aggregates failed", true)
+		sort.Strings(traits)
+
+		common.PrintItem(
+			[]datatable.Field[placement.ResourceProvider]{
+				{Name: "Uuid"}, {Name: "Name"}, {Name: "Generation"},
+				{Name: "RootProviderUuid"}, {Name: "ParentProviderUuid"},
+				{Name: "Aggregates", RenderFunc: func(item placement.ResourceProvider) any {
+					return strings.Join(aggregates, "\n")
+				}},
+				{Name: "Traits", RenderFunc: func(item placement.ResourceProvider) any {
+					return strings.Join(traits, "\n")
+				}},
+			},
+			[]datatable.Field[placement.ResourceProvider]{},
+			*rp, common.TableOptions{},
+		)
+		resources, err := client.PlacementV1().GetProviderResources(rp.Uuid)
+		utility.LogError(err, "get inventories failed", true)
+		PrintProviderResources(resources)
+	},
+}
+
+var providerAllocations = &cobra.Command{
+	Use:   "allocations <provider>",
+	Short: "List allocations on resource provider",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := common.DefaultClient()
+		rp, err := client.PlacementV1().FindResourceProvider(args[0])
+		utility.LogIfError(err, true, "get resource provider %s failed", args[0])
+		allocations, err := client.PlacementV1().GetProviderAllocations(rp.Uuid)
+		utility.LogError(err, "get allocations failed", true)
+
+		items := flattenAllocations(allocations, func(key string) (string, string) {
+			return rp.Uuid, key
+		})
+		common.PrintItems(
+			[]datatable.Column[AllocationItem]{
+				{Name: "Consumer"}, {Name: "ResourceClass"},
+				{Name: "Amount", Align: text.AlignRight},
+			},
+			[]datatable.Column[AllocationItem]{},
+			items,
+			common.TableOptions{SortBy: []table.SortBy{{Name: "Consumer"}, {Name: "ResourceClass"}}},
+		)
+	},
+}
+
+var allocationShow = &cobra.Command{
+	Use:   "show <consumer>",
+	Short: "Show allocations of consumer (e.g. server id or migration id)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := common.DefaultClient()
+		allocations, err := client.PlacementV1().GetAllocations(args[0])
+		utility.LogError(err, "get allocations failed", true)
+		if len(allocations.Allocations) == 0 {
+			console.Warn("consumer %s has no allocations", args[0])
+			return
+		}
+		items := flattenAllocations(allocations.Allocations, func(key string) (string, string) {
+			return key, args[0]
+		})
+		for i, item := range items {
+			if rp, err := client.PlacementV1().GetResourceProvider(item.Provider); err == nil {
+				items[i].ProviderName = rp.Name
+			}
+		}
+		common.PrintItems(
+			[]datatable.Column[AllocationItem]{
+				{Name: "Provider"}, {Name: "ProviderName"},
+				{Name: "ResourceClass"}, {Name: "Amount", Align: text.AlignRight},
+			},
+			[]datatable.Column[AllocationItem]{},
+			items,
+			common.TableOptions{SortBy: []table.SortBy{{Name: "ProviderName"}, {Name: "ResourceClass"}}},
+		)
+	},
+}
+
+type Candidate struct {
+	Provider     string
+	ProviderName string
+	Resources    string
+	Free         string
+}
+
+var candidateList = &cobra.Command{
+	Use:   "candidates",
+	Short: "List allocation candidates",
+	Example: "placement candidates --resource VCPU:2 --resource MEMORY_MB:4096 --resource DISK_GB:20\n" +
+		"placement candidates --resource VCPU:2 --required HW_CPU_X86_AVX2",
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		resources, _ := cmd.Flags().GetStringArray("resource")
+		required, _ := cmd.Flags().GetStringArray("required")
+		limit, _ := cmd.Flags().GetInt("limit")
+		if len(resources) == 0 {
+			console.Fatal("at least one --resource is required")
+		}
+		value, err := parseResources(resources)
+		utility.LogError(err, "invalid resource", true)
+		query := url.Values{"resources": []string{value}}
+		if len(required) > 0 {
+			query.Set("required", strings.Join(required, ","))
+		}
+		if limit > 0 {
+			query.Set("limit", fmt.Sprintf("%d", limit))
+		}
+		client := common.DefaultClient()
+		candidates, err := client.PlacementV1().ListAllocationCandidates(query)
+		utility.LogError(err, "list allocation candidates failed", true)
+
+		providerNames := map[string]string{}
+		if providers, err := client.PlacementV1().ListResourceProviders(nil); err == nil {
+			providerNames = lo.SliceToMap(providers, func(p placement.ResourceProvider) (string, string) {
+				return p.Uuid, p.Name
+			})
+		}
+		items := []Candidate{}
+		for _, request := range candidates.AllocationRequests {
+			for rpUuid, alloc := range request.Allocations {
+				summary := candidates.ProviderSummaries[rpUuid]
+				requested, free := []string{}, []string{}
+				for rc, amount := range alloc.Resources {
+					requested = append(requested, fmt.Sprintf("%s=%d", rc, amount))
+					if r, ok := summary.Resources[rc]; ok {
+						free = append(free, fmt.Sprintf("%s=%d", rc, r.Capacity-r.Used))
+					}
+				}
+				sort.Strings(requested)
+				sort.Strings(free)
+				items = append(items, Candidate{
+					Provider: rpUuid, ProviderName: providerNames[rpUuid],
+					Resources: strings.Join(requested, "\n"), Free: strings.Join(free, "\n"),
+				})
+			}
+		}
+		common.PrintItems(
+			[]datatable.Column[Candidate]{
+				{Name: "Provider"}, {Name: "ProviderName"},
+				{Name: "Resources"}, {Name: "Free"},
+			},
+			[]datatable.Column[Candidate]{},
+			items,
+			common.TableOptions{SeparateRows: true, SortBy: []table.SortBy{{Name: "ProviderName"}}},
+		)
+	},
+}
+
+func init() {
+	providerList.Flags().String("name", "", "List resource providers matched by name")
+	providerList.Flags().StringArray("resource", []string{},
+		"List resource providers having capacity of the resource. "+resourceUsage)
+	providerList.Flags().BoolP("long", "l", false, "List additional fields in output")
+
+	candidateList.Flags().StringArray("resource", []string{}, "Requested resource. "+resourceUsage)
+	candidateList.Flags().StringArray("required", []string{},
+		"Required trait (repeat option to set multiple traits)")
+	candidateList.Flags().Int("limit", 0, "Maximum number of candidates")
+
+	provider.AddCommand(providerList, providerShow, providerAllocations)
+	allocation.AddCommand(allocationShow)
+	PlacementCmd.AddCommand(provider, allocation, candidateList)
+}
@@ -1,32 +1,299 @@
 package quota
 
 import (
+	"fmt"
+
+	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
 	"github.com/BytemanD/skyman/utility"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
 
+const (
+	NOVA    = "compute"
+	CINDER  = "volume"
+	NEUTRON = "network"
+)
+
+// 命令行参数与各服务配额字段的对应关系
+type quotaFlag struct {
+	Service string
+	Flag    string
+	Key     string
+	Usage   string
+}
+
+var quotaFlags = []quotaFlag{
+	{NOVA, "instances", "instances", "Number of instances"},
+	{NOVA, "cores", "cores", "Number of instance cores"},
+	{NOVA, "ram", "ram", "Megabytes of instance RAM"},
+	{NOVA, "key-pairs", "key_pairs", "Number of key pairs"},
+	{NOVA, "server-groups", "server_groups", "Number of server groups"},
+	{NOVA, "server-group-members", "server_group_members", "Number of servers per server group"},
+	{NOVA, "metadata-items", "metadata_items", "Number of metadata items per instance"},
+	{CINDER, "volumes", "volumes", "Number of volumes"},
+	{CINDER, "gigabytes", "gigabytes", "Gigabytes of volumes and snapshots"},
+	{CINDER, "snapshots", "snapshots", "Number of snapshots"},
+	{CINDER, "backups", "backups", "Number of backups"},
+	{CINDER, "backup-gigabytes", "backup_gigabytes", "Gigabytes of backups"},
+	{CINDER, "per-volume-gigabytes", "per_volume_gigabytes", "Max gigabytes per volume"},
+	{NEUTRON, "networks", "network", "Number of networks"},
+	{NEUTRON, "subnets", "subnet", "Number of subnets"},
+	{NEUTRON, "ports", "port", "Number of ports"},
+	{NEUTRON, "routers", "router", "Number of routers"},
+	{NEUTRON, "floating-ips", "floatingip", "Number of floating IPs"},
+	{NEUTRON, "security-groups", "security_group", "Number of security groups"},
+	{NEUTRON, "security-group-rules", "security_group_rule", "Number of security group rules"},
+}
+
+type Quota struct {
+	Service  string
+	Resource string
+	Limit    int
+	InUse    int
+	Reserved int
+}
+
+func limitsToQuotas(service string, limits map[string]int) []Quota {
+	return lo.MapToSlice(limits, func(k string, v int) Quota {
+		return Quota{Service: service, Resource: k, Limit: v}
+	})
+}
+
+func printQuotas(items []Quota, usage bool) {
+	common.PrintItems(
+		[]datatable.Column[Quota]{
+			{Name: "Service"}, {Name: "Resource"},
+			{Name: "Limit", RenderFunc: func(item Quota) any {
+				if item.Limit < 0 {
+					return "unlimited"
+				}
+				return item.Limit
+			}},
+		},
+		[]datatable.Column[Quota]{
+			{Name: "InUse"}, {Name: "Reserved"},
+		},
+		items,
+		common.TableOptions{
+			More:   usage,
+			SortBy: []table.SortBy{{Name: "Service"}, {Name: "Resource"}},
+		},
+	)
+}
+
+func getProjectId(client *openstack.Openstack, project string) string {
+	if project == "" {
+		projectId, err := client.ProjectId()
+		utility.LogError(err, "get project id failed", true)
+		return projectId
+	}
+	p, err := client.KeystoneV3().FindProject(project)
+	utility.LogError(err, "get project failed", true)
+	return p.Id
+}
+
+func getProjectQuotas(client *openstack.Openstack, projectId string, usage bool) []Quota {
+	quotas := []Quota{}
+	if usage {
+		if items, err := client.NovaV2().GetQuotaUsages(projectId); err == nil {
+			for k, v := range items {
+				quotas = append(quotas, Quota{NOVA, k, v.Limit, v.InUse, v.Reserved})
+			}
+		} else {
+			console.Warn("get compute quota failed: %s", err)
+		}
+		if items, err := client.CinderV2().GetQuotaUsages(projectId); err == nil {
+			for k, v := range items {
+				quotas = append(quotas, Quota{CINDER, k, v.Limit, v.InUse, v.Reserved})
+			}
+		} else {
+			console.Warn("get volume quota failed: %s", err)
+		}
+		if items, err := client.NeutronV2().GetQuotaUsages(projectId); err == nil {
+			for k, v := range items {
+				quotas = append(quotas, Quota{NEUTRON, k, v.Limit, v.Used, v.Reserved})
+			}
+		} else {
+			console.Warn("get network quota failed: %s", err)
+		}
+		return quotas
+	}
+	if limits, err := client.NovaV2().GetQuotaLimits(projectId); err == nil {
+		quotas = append(quotas, limitsToQuotas(NOVA, limits)...)
+	} else {
+		console.Warn("get compute quota failed: %s", err)
+	}
+	if limits, err := client.CinderV2().GetQuotaLimits(projectId); err == nil {
+		quotas = append(quotas, limitsToQuotas(CINDER, limits)...)
+	} else {
+		console.Warn("get volume quota failed: %s", err)
+	}
+	if limits, err := client.NeutronV2().GetQuotaLimits(projectId); err == nil {
+		quotas = append(quotas, limitsToQuotas(NEUTRON, limits)...)
+	} else {
+		console.Warn("get network quota failed: %s", err)
+	}
+	return quotas
+}
+
+func getClassQuotas(client *openstack.Openstack, class string) []Quota {
+	quotas := []Quota{}
+	if limits, err := client.NovaV2().GetQuotaClassLimits(class); err == nil {
+		quotas = append(quotas, limitsToQuotas(NOVA, limits)...)
+	} else {
+		console.Warn("get compute quota class failed: %s", err)
+	}
+	if limits, err := client.CinderV2().GetQuotaClassLimits(class); err == nil {
+		quotas = append(quotas, limitsToQuotas(CINDER, limits)...)
+	} else {
+		console.Warn("get volume quota class failed: %s", err)
+	}
+	return quotas
+}
+
 var QuotaCmd = &cobra.Command{Use: "quota"}
 
 var show = &cobra.Command{
 	Use:   "show",
 	Short: "Show quotas for project or class.",
 	Args:  cobra.ExactArgs(0),
-	Run: func(_ *cobra.Command, args []string) {
+	Run: func(cmd *cobra.Command, args []string) {
+		project, _ := cmd.Flags().GetString("project")
+		class, _ := cmd.Flags().GetString("class")
+		usage, _ := cmd.Flags().GetBool("usage")
+
 		client := common.DefaultClient()
-		projectId, err := client.ProjectId()
-		utility.LogError(err, "get project id failed, %v", true)
+		if class != "" {
+			printQuotas(getClassQuotas(client, class), false)
+			return
+		}
+		projectId := getProjectId(client, project)
+		printQuotas(getProjectQuotas(client, projectId, usage), usage)
+	},
+}
+
+var set = &cobra.Command{
+	Use:   "set",
+	Short: "Set quotas for project or class.",
+	Example: "quota set --project demo --instances 20 --cores 40 --volumes 20 --ports 100\n" +
+		"quota set --class default --instances 20",
+	Args: cobra.ExactArgs(0),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		project, _ := cmd.Flags().GetString("project")
+		class, _ := cmd.Flags().GetString("class")
+		if project == "" && class == "" {
+			return fmt.Errorf("--project or --class is required")
+		}
+		if project != "" && class != "" {
+			return fmt.Errorf("--project and --class are mutually exclusive")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		project, _ := cmd.Flags().GetString("project")
+		class, _ := cmd.Flags().GetString("class")
+
+		limits := map[string]map[string]int{}
+		for _, f := range quotaFlags {
+			if !cmd.Flags().Changed(f.Flag) {
+				continue
+			}
+			value, _ := cmd.Flags().GetInt(f.Flag)
+			if _, ok := limits[f.Service]; !ok {
+				limits[f.Service] = map[string]int{}
+			}
+			limits[f.Service][f.Key] = value
+		}
+		if len(limits) == 0 {
+			console.Fatal("nothing to update")
+		}
+		if class != "" && len(limits[NEUTRON]) > 0 {
+			console.Fatal("network quotas do not support quota class")
+		}
+
+		client := common.DefaultClient()
+		quotas := []Quota{}
+		if class != "" {
+			if len(limits[NOVA]) > 0 {
+				updated, err := client.NovaV2().UpdateQuotaClassLimits(class, limits[NOVA])
+				utility.LogError(err, "update compute quota class failed", true)
+				quotas = append(quotas, limitsToQuotas(NOVA, updated)...)
+			}
+			if len(limits[CINDER]) > 0 {
+				updated, err := client.CinderV2().UpdateQuotaClassLimits(class, limits[CINDER])
+				utility.LogError(err, "update volume quota class failed", true)
+				quotas = append(quotas, limitsToQuotas(CINDER, updated)...)
+			}
+			printQuotas(quotas, false)
+			return
+		}
+		projectId := getProjectId(client, project)
+		if len(limits[NOVA]) > 0 {
+			updated, err := client.NovaV2().UpdateQuotaLimits(projectId, limits[NOVA])
+			utility.LogError(err, "update compute quota failed", true)
+			quotas = append(quotas, limitsToQuotas(NOVA, updated)...)
+		}
+		if len(limits[CINDER]) > 0 {
+			updated, err := client.CinderV2().UpdateQuotaLimits(projectId, limits[CINDER])
+			utility.LogError(err, "update volume quota failed", true)
+			quotas = append(quotas, limitsToQuotas(CINDER, updated)...)
+		}
+		if len(limits[NEUTRON]) > 0 {
+			updated, err := client.NeutronV2().UpdateQuotaLimits(projectId, limits[NEUTRON])
+			utility.LogError(err, "update network quota failed", true)
+			quotas = append(quotas, limitsToQuotas(NEUTRON, updated)...)
+		}
+		printQuotas(quotas, false)
+	},
+}
+
+var defaultQuota = &cobra.Command{
+	Use:   "default",
+	Short: "Show default quotas for project.",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		project, _ := cmd.Flags().GetString("project")
 
-		quotaSet, err := client.NovaV2().GetQuotaSet(projectId)
-		utility.LogError(err, "show quota failed, %v", true)
-		var showFipAndFixedIps bool
-		if !client.NovaV2().MicroVersionLargeEqual("2.36") {
-			showFipAndFixedIps = true
+		client := common.DefaultClient()
+		projectId := getProjectId(client, project)
+		quotas := []Quota{}
+		if limits, err := client.NovaV2().GetDefaultQuotaLimits(projectId); err == nil {
+			quotas = append(quotas, limitsToQuotas(NOVA, limits)...)
+		} else {
+			console.Warn("get compute default quota failed: %s", err)
+		}
+		if limits, err := client.CinderV2().GetDefaultQuotaLimits(projectId); err == nil {
+			quotas = append(quotas, limitsToQuotas(CINDER, limits)...)
+		} else {
+			console.Warn("get volume default quota failed: %s", err)
+		}
+		if limits, err := client.NeutronV2().GetDefaultQuotaLimits(projectId); err == nil {
+			quotas = append(quotas, limitsToQuotas(NEUTRON, limits)...)
+		} else {
+			console.Warn("get network default quota failed: %s", err)
 		}
-		common.PrintQuotaSet(*quotaSet, showFipAndFixedIps)
+		printQuotas(quotas, false)
 	},
 }
 
 func init() {
-	QuotaCmd.AddCommand(show)
+	show.Flags().String("project", "", "Project name or id, defaults to current project")
+	show.Flags().String("class", "", "Show quotas of this quota class")
+	show.Flags().Bool("usage", false, "Show quota usage")
+	show.MarkFlagsMutuallyExclusive("project", "class")
+
+	set.Flags().String("project", "", "Project name or id")
+	set.Flags().String("class", "", "Quota class name")
+	for _, f := range quotaFlags {
+		set.Flags().Int(f.Flag, 0, f.Usage)
+	}
+
+	defaultQuota.Flags().String("project", "", "Project name or id, defaults to current project")
+
+	QuotaCmd.AddCommand(show, set, defaultQuota)
 }
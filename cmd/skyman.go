@@ -22,6 +22,7 @@ import (
 	"github.com/BytemanD/skyman/cmd/keystone"
 
 	"github.com/BytemanD/skyman/cmd/nova"
+	"github.com/BytemanD/skyman/cmd/placement"
 	"github.com/BytemanD/skyman/cmd/quota"
 	"github.com/BytemanD/skyman/cmd/templates"
 	"github.com/BytemanD/skyman/cmd/test"
@@ -176,14 +177,15 @@ func main() {
 
 		nova.Server, nova.Flavor, nova.Hypervisor,
 		nova.Keypair, nova.Compute, nova.Console,
-		nova.Migration, nova.AZ, nova.Aggregate,
+		nova.Migration, nova.AZ, nova.Aggregate, nova.Usage,
 		glance.Image,
 		cinder.Volume, cinder.Snapshot, cinder.Backup,
 
 		neutron.Router, neutron.Network, neutron.Subnet, neutron.Port,
-		neutron.Security, neutron.SG,
+		neutron.Security, neutron.SG, neutron.Qos,
 
 		quota.QuotaCmd,
+		placement.PlacementCmd,
 		templates.DefineCmd, templates.UndefineCmd,
 		tool.ToolCmd,
 		TestCmd,
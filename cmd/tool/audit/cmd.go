@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var AuditCmd = &cobra.Command{Use: "audit", Short: "audit resources"}
+
+func init() {
+	AuditCmd.AddCommand(
+		securityGroupAudit,
+	)
+}
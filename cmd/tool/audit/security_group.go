@@ -0,0 +1,302 @@
+package audit
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/datatable"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const (
+	SEVERITY_HIGH   = "high"
+	SEVERITY_MEDIUM = "medium"
+	SEVERITY_LOW    = "low"
+
+	SOURCE_ANY = "0.0.0.0/0"
+)
+
+var (
+	severityOrder = []string{SEVERITY_HIGH, SEVERITY_MEDIUM, SEVERITY_LOW}
+	// 默认检查 SSH、RDP 以及常见数据库端口
+	defaultRiskyPorts = []int{22, 3389, 3306, 5432, 1433, 1521, 6379, 27017}
+)
+
+type sgFinding struct {
+	Severity        string   `json:"severity"`
+	SecurityGroup   string   `json:"security_group"`
+	SecurityGroupId string   `json:"security_group_id"`
+	ProjectId       string   `json:"project_id"`
+	Port            int      `json:"port"`
+	Protocol        string   `json:"protocol"`
+	RuleId          string   `json:"rule_id"`
+	Source          string   `json:"source"`
+	Servers         []string `json:"servers"`
+	Ports           []string `json:"ports"`
+}
+
+func isAnyRemote(rule neutron.SecurityGroupRule) bool {
+	return rule.RemoteGroupId == "" &&
+		lo.Contains([]string{"", "0.0.0.0/0", "::/0"}, rule.RemoteIpPrefix)
+}
+
+// 掩码长度小于 /16 (IPv6 小于 /48) 的地址段视为过宽
+func isBroadRemote(rule neutron.SecurityGroupRule) bool {
+	if rule.RemoteGroupId != "" || rule.RemoteIpPrefix == "" {
+		return false
+	}
+	_, ipNet, err := net.ParseCIDR(rule.RemoteIpPrefix)
+	if err != nil {
+		return false
+	}
+	ones, bits := ipNet.Mask.Size()
+	if bits == 32 {
+		return ones < 16
+	}
+	return ones < 48
+}
+
+func ruleCoversPort(rule neutron.SecurityGroupRule, port int) bool {
+	if rule.Direction != "ingress" {
+		return false
+	}
+	if !lo.Contains([]string{"", "any", "tcp", "6", "udp", "17"}, strings.ToLower(rule.Protocol)) {
+		return false
+	}
+	if rule.PortRangeMin != nil && *rule.PortRangeMin > port {
+		return false
+	}
+	return rule.PortRangeMax == nil || port <= *rule.PortRangeMax
+}
+
+// 解析 remote_group_id 链路, 找出可以从任意地址访问的安全组
+type sgResolver struct {
+	client  *openstack.Openstack
+	groups  map[string]*neutron.SecurityGroup
+	paths   map[string][]string
+	visited map[string]bool
+}
+
+func (r *sgResolver) getGroup(id string) *neutron.SecurityGroup {
+	if sg, ok := r.groups[id]; ok {
+		return sg
+	}
+	sg, err := r.client.NeutronV2().GetSecurityGroup(id)
+	if err != nil {
+		console.Warn("get security group %s failed: %s", id, err)
+		sg = nil
+	}
+	r.groups[id] = sg
+	return sg
+}
+func (r *sgResolver) groupName(id string) string {
+	if sg := r.getGroup(id); sg != nil && sg.Name != "" {
+		return sg.Name
+	}
+	return id
+}
+
+// 返回安全组暴露给任意地址的链路, 例如: [db, web, 0.0.0.0/0], 不可达时返回 nil
+func (r *sgResolver) exposedPath(id string) []string {
+	if path, ok := r.paths[id]; ok {
+		return path
+	}
+	if r.visited[id] {
+		return nil
+	}
+	r.visited[id] = true
+	defer delete(r.visited, id)
+
+	sg := r.getGroup(id)
+	if sg == nil {
+		return nil
+	}
+	var path []string
+	for _, rule := range sg.Rules {
+		if rule.Direction == "ingress" && isAnyRemote(rule) {
+			path = []string{r.groupName(id), SOURCE_ANY}
+			break
+		}
+	}
+	if path == nil {
+		for _, rule := range sg.Rules {
+			if rule.Direction != "ingress" || rule.RemoteGroupId == "" || rule.RemoteGroupId == id {
+				continue
+			}
+			if remotePath := r.exposedPath(rule.RemoteGroupId); remotePath != nil {
+				path = append([]string{r.groupName(id)}, remotePath...)
+				break
+			}
+		}
+	}
+	// 环路中的中间结果可能不完整, 只缓存可达或最外层的结果
+	if path != nil || len(r.visited) == 1 {
+		r.paths[id] = path
+	}
+	return path
+}
+
+func auditSecurityGroups(resolver *sgResolver, sgs []neutron.SecurityGroup, riskyPorts []int) []sgFinding {
+	findings := []sgFinding{}
+	found := map[string]bool{}
+	for _, sg := range sgs {
+		for _, rule := range sg.Rules {
+			for _, port := range riskyPorts {
+				if !ruleCoversPort(rule, port) {
+					continue
+				}
+				finding := sgFinding{
+					SecurityGroup: sg.Name, SecurityGroupId: sg.Id, ProjectId: sg.ProjectId,
+					Port: port, Protocol: lo.CoalesceOrEmpty(rule.Protocol, "any"), RuleId: rule.Id,
+					Servers: []string{}, Ports: []string{},
+				}
+				switch {
+				case isAnyRemote(rule):
+					finding.Severity = SEVERITY_HIGH
+					finding.Source = lo.CoalesceOrEmpty(rule.RemoteIpPrefix, SOURCE_ANY)
+				case rule.RemoteGroupId != "":
+					path := resolver.exposedPath(rule.RemoteGroupId)
+					if path == nil {
+						continue
+					}
+					finding.Severity = SEVERITY_MEDIUM
+					finding.Source = strings.Join(path, " <- ")
+				case isBroadRemote(rule):
+					finding.Severity = SEVERITY_LOW
+					finding.Source = rule.RemoteIpPrefix
+				default:
+					continue
+				}
+				key := fmt.Sprintf("%s|%d|%s", sg.Id, port, finding.Source)
+				if found[key] {
+					continue
+				}
+				found[key] = true
+				findings = append(findings, finding)
+			}
+		}
+	}
+	slices.SortFunc(findings, func(a, b sgFinding) int {
+		if a.Severity != b.Severity {
+			return slices.Index(severityOrder, a.Severity) - slices.Index(severityOrder, b.Severity)
+		}
+		if a.SecurityGroup != b.SecurityGroup {
+			return strings.Compare(a.SecurityGroup, b.SecurityGroup)
+		}
+		return a.Port - b.Port
+	})
+	return findings
+}
+
+// 通过端口的 device_id 找到使用安全组的虚拟机
+func fillFindingServers(findings []sgFinding, ports []neutron.Port, servers []nova.Server) {
+	serverNames := lo.SliceToMap(servers, func(s nova.Server) (string, string) {
+		return s.Id, s.Name
+	})
+	for i := range findings {
+		for _, port := range ports {
+			if !lo.Contains(port.SecurityGroups, findings[i].SecurityGroupId) {
+				continue
+			}
+			findings[i].Ports = append(findings[i].Ports, port.Id)
+			if !strings.HasPrefix(port.DeviceOwner, "compute:") || port.DeviceId == "" {
+				continue
+			}
+			server := lo.CoalesceOrEmpty(serverNames[port.DeviceId], port.DeviceId)
+			if !lo.Contains(findings[i].Servers, server) {
+				findings[i].Servers = append(findings[i].Servers, server)
+			}
+		}
+	}
+}
+
+var securityGroupAudit = &cobra.Command{
+	Use:   "security-groups",
+	Short: "Find servers exposed to any address on risky ports",
+	Long: "Scan ingress rules of security groups for risky ports, severity of findings:\n" +
+		"  high:   port is open to 0.0.0.0/0 or ::/0\n" +
+		"  medium: port is open to a remote group which is exposed to any address\n" +
+		"  low:    port is open to a broad CIDR (shorter than /16 for IPv4, /48 for IPv6)",
+	Example: "tool audit security-groups\n" +
+		"tool audit security-groups --all-projects --ports 22,3389,3306 -f json",
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		allProjects, _ := cmd.Flags().GetBool("all-projects")
+		riskyPorts, _ := cmd.Flags().GetIntSlice("ports")
+		inUse, _ := cmd.Flags().GetBool("in-use")
+		long, _ := cmd.Flags().GetBool("long")
+
+		client := common.DefaultClient()
+		query := url.Values{}
+		serverQuery := url.Values{}
+		if allProjects {
+			serverQuery.Set("all_tenants", "1")
+		} else {
+			projectId, err := client.ProjectId()
+			utility.LogError(err, "get project id failed", true)
+			query.Set("project_id", projectId)
+		}
+		sgs, err := client.NeutronV2().ListSecurityGroup(query)
+		utility.LogError(err, "list security groups failed", true)
+		resolver := &sgResolver{
+			client: client, groups: map[string]*neutron.SecurityGroup{},
+			paths: map[string][]string{}, visited: map[string]bool{},
+		}
+		for i := range sgs {
+			resolver.groups[sgs[i].Id] = &sgs[i]
+		}
+		findings := auditSecurityGroups(resolver, sgs, riskyPorts)
+
+		if len(findings) > 0 {
+			ports, err := client.NeutronV2().ListPort(query)
+			utility.LogError(err, "list ports failed", true)
+			servers, err := client.NovaV2().ListServer(serverQuery, true)
+			utility.LogError(err, "list servers failed", true)
+			fillFindingServers(findings, ports, servers)
+		}
+		if inUse {
+			findings = lo.Filter(findings, func(f sgFinding, _ int) bool { return len(f.Ports) > 0 })
+		}
+		common.PrintItems(
+			[]datatable.Column[sgFinding]{
+				{Name: "Severity", RenderFunc: func(item sgFinding) any {
+					return strings.ToUpper(item.Severity)
+				}},
+				{Name: "SecurityGroup"}, {Name: "Port"}, {Name: "Protocol"}, {Name: "Source"},
+				{Name: "Servers", RenderFunc: func(item sgFinding) any {
+					return strings.Join(item.Servers, "\n")
+				}},
+			},
+			[]datatable.Column[sgFinding]{
+				{Name: "SecurityGroupId"}, {Name: "ProjectId"}, {Name: "RuleId"},
+				{Name: "Ports", RenderFunc: func(item sgFinding) any {
+					return len(item.Ports)
+				}},
+			},
+			findings, common.TableOptions{More: long},
+		)
+		if len(findings) > 0 {
+			counts := lo.CountValuesBy(findings, func(f sgFinding) string { return f.Severity })
+			console.Warn("found %d finding(s): high=%d, medium=%d, low=%d",
+				len(findings), counts[SEVERITY_HIGH], counts[SEVERITY_MEDIUM], counts[SEVERITY_LOW])
+		}
+	},
+}
+
+func init() {
+	securityGroupAudit.Flags().Bool("all-projects", false, "Audit security groups of all projects")
+	securityGroupAudit.Flags().IntSlice("ports", defaultRiskyPorts, "Risky ports to check")
+	securityGroupAudit.Flags().Bool("in-use", false, "Only show security groups used by ports")
+	securityGroupAudit.Flags().BoolP("long", "l", false, "List additional fields in output")
+}
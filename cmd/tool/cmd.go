@@ -1,7 +1,9 @@
 package tool
 
 import (
+	"github.com/BytemanD/skyman/cmd/tool/audit"
 	"github.com/BytemanD/skyman/cmd/tool/guest"
+	"github.com/BytemanD/skyman/cmd/tool/host"
 	"github.com/BytemanD/skyman/cmd/tool/neutron"
 	"github.com/BytemanD/skyman/cmd/tool/prune"
 	"github.com/BytemanD/skyman/cmd/tool/server"
@@ -17,5 +19,7 @@ func init() {
 		server.FlavorCommand,
 		prune.PruneCmd,
 		neutron.Vpc,
+		host.HostCommand,
+		audit.AuditCmd,
 	)
 }
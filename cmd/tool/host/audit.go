@@ -0,0 +1,175 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/guest"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/openstack/session"
+	"github.com/BytemanD/skyman/utility"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+const (
+	AUDIT_OK       = "ok"
+	AUDIT_ORPHAN   = "orphan"
+	AUDIT_MISSING  = "missing"
+	AUDIT_MISMATCH = "mismatch"
+)
+
+type auditItem struct {
+	Result      string
+	UUID        string
+	Domain      string
+	DomainState string
+	Server      string
+	Status      string
+	PowerState  string
+	Message     string
+}
+
+// 获取 libvirt 的连接地址, 默认使用 hypervisor 的 IP
+func getHostConnection(client *openstack.Openstack, host string) (string, error) {
+	hypervisors, err := client.NovaV2().ListHypervisor(
+		url.Values{"hypervisor_hostname_pattern": []string{host}}, true)
+	if err != nil {
+		return "", err
+	}
+	hypervisor, ok := lo.Find(hypervisors, func(h nova.Hypervisor) bool {
+		return h.Host == host || h.HypervisorHostname == host
+	})
+	if !ok || hypervisor.HostIp == "" {
+		return "", fmt.Errorf("hypervisor of host %s not found", host)
+	}
+	return hypervisor.HostIp, nil
+}
+
+// 对比 libvirt 域与 nova 虚拟机
+func auditHost(client *openstack.Openstack, host string, domains []guest.Domain, servers []nova.Server) []auditItem {
+	items := []auditItem{}
+	serverMap := lo.SliceToMap(servers, func(s nova.Server) (string, nova.Server) {
+		return s.Id, s
+	})
+	for _, domain := range domains {
+		item := auditItem{UUID: domain.UUID, Domain: domain.Name, DomainState: domain.StateName()}
+		server, ok := serverMap[domain.UUID]
+		if !ok {
+			item.Result = AUDIT_ORPHAN
+			// 迁移或 resize 过程中源节点上的域也会被当作孤儿域
+			s, err := client.NovaV2().GetServer(domain.UUID)
+			switch {
+			case err == nil:
+				item.Server, item.Status, item.PowerState = s.Name, s.Status, s.GetPowerState()
+				item.Message = fmt.Sprintf("server is on host %s", s.Host)
+				if s.TaskState != "" {
+					item.Message += fmt.Sprintf(", task state is %s", s.TaskState)
+				}
+			case errors.Is(err, session.ErrHTTP404):
+				item.Message = "server not found in nova"
+			default:
+				item.Message = fmt.Sprintf("get server failed: %s", err)
+			}
+			items = append(items, item)
+			continue
+		}
+		item.Server, item.Status, item.PowerState = server.Name, server.Status, server.GetPowerState()
+		switch {
+		case domain.PowerState() == server.PowerState:
+			item.Result = AUDIT_OK
+		case server.TaskState != "":
+			item.Result = AUDIT_OK
+			item.Message = fmt.Sprintf("task state is %s", server.TaskState)
+		default:
+			item.Result = AUDIT_MISMATCH
+			item.Message = fmt.Sprintf("power state in nova is %s, but domain is %s",
+				server.GetPowerState(), domain.StateName())
+		}
+		items = append(items, item)
+	}
+	domainUUIDs := lo.Map(domains, func(d guest.Domain, _ int) string { return d.UUID })
+	for _, server := range servers {
+		if lo.Contains(domainUUIDs, server.Id) || server.StatusIs("SHELVED_OFFLOADED") {
+			continue
+		}
+		item := auditItem{
+			Result: AUDIT_MISSING, UUID: server.Id,
+			Server: server.Name, Status: server.Status, PowerState: server.GetPowerState(),
+			Message: fmt.Sprintf("domain %s not found on %s", server.InstanceName, host),
+		}
+		if server.TaskState != "" {
+			item.Message += fmt.Sprintf(", task state is %s", server.TaskState)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+var hostAudit = &cobra.Command{
+	Use:   "audit <host>",
+	Short: "Check libvirt domains on host against nova servers",
+	Long: "Check libvirt domains on host against nova servers, report:\n" +
+		"  orphan: domains that nova servers on this host don't know about\n" +
+		"  missing: nova servers on this host without domain\n" +
+		"  mismatch: power state in nova is different from domain state",
+	Example: "tool host audit compute-1\n" +
+		"tool host audit compute-1 --connection 192.168.1.10 --all",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		host := args[0]
+		connection, _ := cmd.Flags().GetString("connection")
+		all, _ := cmd.Flags().GetBool("all")
+		long, _ := cmd.Flags().GetBool("long")
+
+		client := common.DefaultClient()
+		if connection == "" {
+			var err error
+			connection, err = getHostConnection(client, host)
+			utility.LogIfError(err, true, "get connection of %s failed", host)
+		}
+		console.Info("list domains from %s", connection)
+		domains, err := guest.ListDomains(connection)
+		utility.LogIfError(err, true, "list domains on %s failed", connection)
+		servers, err := listHostServers(client, host)
+		utility.LogIfError(err, true, "list servers on %s failed", host)
+
+		items := auditHost(client, host, domains, servers)
+		problems := lo.CountBy(items, func(item auditItem) bool { return item.Result != AUDIT_OK })
+		if !all {
+			items = lo.Filter(items, func(item auditItem, _ int) bool { return item.Result != AUDIT_OK })
+		}
+		pt := common.PrettyTable{
+			ShortColumns: []common.Column{
+				{Name: "Result", AutoColor: true}, {Name: "UUID"},
+				{Name: "DomainState", AutoColor: true},
+				{Name: "Status", AutoColor: true}, {Name: "PowerState", AutoColor: true},
+				{Name: "Message"},
+			},
+			LongColumns: []common.Column{
+				{Name: "Domain"}, {Name: "Server"},
+			},
+		}
+		pt.AddItems(items)
+		common.PrintPrettyTable(pt, long)
+		if problems > 0 {
+			console.Warn("found %d problem(s) in %d domain(s) and %d server(s) on %s",
+				problems, len(domains), len(servers), host)
+		} else {
+			console.Success("%d domain(s) and %d server(s) on %s are consistent",
+				len(domains), len(servers), host)
+		}
+	},
+}
+
+func init() {
+	hostAudit.Flags().String("connection", "", "Address of libvirt, defaults to IP of hypervisor")
+	hostAudit.Flags().Bool("all", false, "Show all domains, including consistent ones")
+	hostAudit.Flags().BoolP("long", "l", false, "List additional fields in output")
+
+	HostCommand.AddCommand(hostAudit)
+}
@@ -0,0 +1,233 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/BytemanD/easygo/pkg/syncutils"
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/common/i18n"
+	"github.com/BytemanD/skyman/openstack"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/utility"
+	"github.com/spf13/cobra"
+)
+
+var HostCommand = &cobra.Command{Use: "host", Short: "compute host tools"}
+
+const (
+	ACTION_LIVE_MIGRATE = "live-migrate"
+	ACTION_MIGRATE      = "migrate"
+	ACTION_EVACUATE     = "evacuate"
+	ACTION_SKIP         = "skip"
+
+	RESULT_SUCCESS = "success"
+	RESULT_FAILED  = "failed"
+	RESULT_SKIPPED = "skipped"
+)
+
+var (
+	errServerNotMoved = errors.New("server is not moved")
+	errServerStayed   = errors.New("server is still on source host")
+)
+
+// 迁移计划中的一项
+type moveTask struct {
+	Server     nova.Server
+	Action     string
+	SourceHost string
+	DestHost   string
+	Result     string
+	Attempts   int
+	Message    string
+}
+
+func (t moveTask) Id() string {
+	return t.Server.Id
+}
+func (t moveTask) Name() string {
+	return t.Server.Name
+}
+func (t moveTask) Status() string {
+	return t.Server.Status
+}
+
+type moveOptions struct {
+	Parallel     int
+	Retries      int
+	Timeout      time.Duration
+	TargetHost   string
+	BlockMigrate bool
+	Password     string
+	Force        bool
+}
+
+func listHostServers(client *openstack.Openstack, host string) ([]nova.Server, error) {
+	query := url.Values{"host": []string{host}, "all_tenants": []string{"1"}}
+	return client.NovaV2().ListServer(query, true)
+}
+
+func printMoveTasks(tasks []moveTask, long bool) {
+	pt := common.PrettyTable{
+		ShortColumns: []common.Column{
+			{Name: "Id", Slot: func(item any) any {
+				return item.(moveTask).Id()
+			}},
+			{Name: "Name", Slot: func(item any) any {
+				return item.(moveTask).Name()
+			}},
+			{Name: "Status", AutoColor: true, Slot: func(item any) any {
+				return item.(moveTask).Status()
+			}},
+			{Name: "Action"},
+			{Name: "SourceHost"}, {Name: "DestHost"},
+			{Name: "Result", AutoColor: true},
+		},
+		LongColumns: []common.Column{
+			{Name: "Attempts"}, {Name: "Message"},
+		},
+	}
+	pt.AddItems(tasks)
+	common.PrintPrettyTable(pt, long)
+}
+
+// 等待虚拟机离开源节点
+func waitServerMoved(client *openstack.Openstack, id string, srcHost string, timeout time.Duration) (*nova.Server, error) {
+	var server *nova.Server
+	err := utility.RetryWithError(
+		utility.RetryCondition{
+			Timeout:      timeout,
+			IntervalMin:  time.Second * 2,
+			IntervalMax:  time.Second * 10,
+			IntervalStep: time.Second * 2,
+		},
+		errServerNotMoved,
+		func() error {
+			s, err := client.NovaV2().GetServer(id)
+			if err != nil {
+				return err
+			}
+			server = s
+			console.Info("[%s] %s, host: %s", id, s.AllStatus(), s.Host)
+			if s.IsError() {
+				return fmt.Errorf("server is error: %s", s.Fault.Message)
+			}
+			if s.TaskState != "" || s.IsMigrating() || s.StatusIs("REBUILD") {
+				return fmt.Errorf("%w: task state is %s", errServerNotMoved, s.TaskState)
+			}
+			if s.Host == srcHost {
+				// 任务已结束但节点未变化, 说明迁移失败, 重试等待没有意义
+				return errServerStayed
+			}
+			return nil
+		},
+	)
+	if errors.Is(err, errServerStayed) {
+		return server, fmt.Errorf("server is still on %s after task finished", srcHost)
+	}
+	return server, err
+}
+
+func moveServer(client *openstack.Openstack, task *moveTask, opt moveOptions) error {
+	c := client.NovaV2()
+	var err error
+	switch task.Action {
+	case ACTION_LIVE_MIGRATE:
+		err = c.ServerLiveMigrate(task.Server.Id, opt.BlockMigrate, opt.TargetHost)
+	case ACTION_MIGRATE:
+		err = c.ServerMigrate(task.Server.Id, opt.TargetHost)
+	case ACTION_EVACUATE:
+		err = c.EvacuateServer(task.Server.Id, opt.Password, opt.TargetHost, opt.Force)
+	default:
+		return fmt.Errorf("invalid action %s", task.Action)
+	}
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", task.Action, err)
+	}
+	server, err := waitServerMoved(client, task.Server.Id, task.SourceHost, opt.Timeout)
+	if err != nil {
+		return err
+	}
+	if server.StatusIs("VERIFY_RESIZE") {
+		console.Info("[%s] confirm resize", server.Id)
+		if err := c.ResizeConfirm(server.Id); err != nil {
+			return fmt.Errorf("confirm resize failed: %w", err)
+		}
+	}
+	task.DestHost = server.Host
+	return nil
+}
+
+// 并发执行迁移, 每个虚拟机失败后最多重试 opt.Retries 次
+func runMoveTasks(client *openstack.Openstack, tasks []moveTask, opt moveOptions) []moveTask {
+	mu := sync.Mutex{}
+	results := []moveTask{}
+	taskGroup := syncutils.TaskGroup[moveTask]{
+		Items:        tasks,
+		MaxWorker:    max(opt.Parallel, 1),
+		Title:        fmt.Sprintf("move %d server(s)", len(tasks)),
+		ShowProgress: true,
+		Func: func(task moveTask) error {
+			defer func() {
+				mu.Lock()
+				results = append(results, task)
+				mu.Unlock()
+			}()
+			if task.Action == ACTION_SKIP {
+				task.Result = RESULT_SKIPPED
+				return nil
+			}
+			var err error
+			for task.Attempts = 1; task.Attempts <= opt.Retries+1; task.Attempts++ {
+				console.Info("[%s] %s (attempt %d)", task.Server.Id, task.Action, task.Attempts)
+				if err = moveServer(client, &task, opt); err == nil {
+					break
+				}
+				console.Warn("[%s] %s failed: %s", task.Server.Id, task.Action, err)
+			}
+			task.Attempts = min(task.Attempts, opt.Retries+1)
+			if err != nil {
+				task.Result = RESULT_FAILED
+				task.Message = err.Error()
+				return err
+			}
+			console.Success("[%s] %s success, %s -> %s",
+				task.Server.Id, task.Action, task.SourceHost, task.DestHost)
+			task.Result = RESULT_SUCCESS
+			return nil
+		},
+	}
+	taskGroup.Start()
+	return results
+}
+
+func registerMoveFlags(cmd *cobra.Command) {
+	cmd.Flags().String("target-host", "", "Destination host, scheduled by nova if empty")
+	cmd.Flags().Int("parallel", 2, "Number of servers moved at the same time")
+	cmd.Flags().Int("retries", 1, "Number of retries for each server")
+	cmd.Flags().Duration("timeout", time.Minute*30, "Timeout for each server")
+	cmd.Flags().Bool("dry-run", false, "Only show the plan")
+	cmd.Flags().BoolP("yes", "y", false, i18n.T("answerYes"))
+	cmd.Flags().BoolP("long", "l", false, "List additional fields in output")
+}
+
+func getMoveOptions(cmd *cobra.Command) moveOptions {
+	targetHost, _ := cmd.Flags().GetString("target-host")
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	retries, _ := cmd.Flags().GetInt("retries")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	return moveOptions{
+		TargetHost: targetHost,
+		Parallel:   parallel,
+		Retries:    max(retries, 0),
+		Timeout:    timeout,
+	}
+}
+
+func init() {
+	HostCommand.AddCommand(hostDrain, hostEvacuate)
+}
@@ -0,0 +1,98 @@
+package host
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/utility"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func drainAction(server nova.Server, cold bool) string {
+	switch {
+	case server.IsActive() || server.IsPaused():
+		if cold {
+			return ACTION_MIGRATE
+		}
+		return ACTION_LIVE_MIGRATE
+	case server.IsStopped():
+		return ACTION_MIGRATE
+	default:
+		return ACTION_SKIP
+	}
+}
+
+var hostDrain = &cobra.Command{
+	Use:   "drain <host>",
+	Short: "Disable compute service and migrate all servers from host",
+	Example: "tool host drain compute-1 --reason 'replace disk'\n" +
+		"tool host drain compute-1 --dry-run",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		host := args[0]
+		reason, _ := cmd.Flags().GetString("reason")
+		cold, _ := cmd.Flags().GetBool("cold")
+		blockMigrate, _ := cmd.Flags().GetBool("block-migrate")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		long, _ := cmd.Flags().GetBool("long")
+		opt := getMoveOptions(cmd)
+		opt.BlockMigrate = blockMigrate
+
+		if opt.TargetHost == host {
+			console.Fatal("target host must be different from %s", host)
+		}
+		client := common.DefaultClient()
+		service, err := client.NovaV2().GetByHostBinary(host, "nova-compute")
+		utility.LogIfError(err, true, "get compute service of %s failed", host)
+
+		listTasks := func() []moveTask {
+			servers, err := listHostServers(client, host)
+			utility.LogIfError(err, true, "list servers on %s failed", host)
+			return lo.Map(servers, func(server nova.Server, _ int) moveTask {
+				return moveTask{
+					Server:     server,
+					Action:     drainAction(server, cold),
+					SourceHost: host,
+				}
+			})
+		}
+		tasks := listTasks()
+		if dryRun {
+			console.Info("service %s:%s status is %s, it will be disabled",
+				service.Host, service.Binary, service.Status)
+			printMoveTasks(tasks, long)
+			return
+		}
+		if !yes {
+			printMoveTasks(tasks, long)
+			if !utility.DefaultScanComfirm(fmt.Sprintf("disable %s and move %d server(s)", host, len(tasks))) {
+				return
+			}
+		}
+		console.Info("disable compute service %s", host)
+		_, err = client.NovaV2().DisableService(host, "nova-compute", reason)
+		utility.LogIfError(err, true, "disable compute service %s failed", host)
+		// 禁用服务前可能有新的虚拟机调度到该节点, 禁用后重新查询
+		if tasks = listTasks(); len(tasks) > 0 {
+			console.Info("found %d server(s) on %s after service is disabled", len(tasks), host)
+		}
+
+		results := runMoveTasks(client, tasks, opt)
+		printMoveTasks(results, long)
+		if lo.ContainsBy(results, func(t moveTask) bool { return t.Result == RESULT_FAILED }) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	registerMoveFlags(hostDrain)
+	hostDrain.Flags().String("reason", "drained by skyman", "Reason for disabling compute service")
+	hostDrain.Flags().Bool("cold", false, "Cold migrate active servers instead of live migrate")
+	hostDrain.Flags().Bool("block-migrate", false, "Use block migration for live migrate")
+}
@@ -0,0 +1,77 @@
+package host
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/common"
+	"github.com/BytemanD/skyman/openstack/model/nova"
+	"github.com/BytemanD/skyman/utility"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func evacuateAction(server nova.Server) string {
+	if server.IsShelved() || server.StatusIs("BUILD") {
+		return ACTION_SKIP
+	}
+	return ACTION_EVACUATE
+}
+
+var hostEvacuate = &cobra.Command{
+	Use:   "evacuate <host>",
+	Short: "Evacuate all servers from a down host",
+	Example: "tool host evacuate compute-1\n" +
+		"tool host evacuate compute-1 --target-host compute-2 --dry-run",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		host := args[0]
+		password, _ := cmd.Flags().GetString("password")
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		long, _ := cmd.Flags().GetBool("long")
+		opt := getMoveOptions(cmd)
+		opt.Password = password
+		opt.Force = force
+
+		if opt.TargetHost == host {
+			console.Fatal("target host must be different from %s", host)
+		}
+		client := common.DefaultClient()
+		service, err := client.NovaV2().GetByHostBinary(host, "nova-compute")
+		utility.LogIfError(err, true, "get compute service of %s failed", host)
+		if service.State != "down" && !service.ForcedDown {
+			console.Fatal("compute service of %s is %s, evacuate requires the host to be down", host, service.State)
+		}
+
+		servers, err := listHostServers(client, host)
+		utility.LogIfError(err, true, "list servers on %s failed", host)
+
+		tasks := lo.Map(servers, func(server nova.Server, _ int) moveTask {
+			return moveTask{Server: server, Action: evacuateAction(server), SourceHost: host}
+		})
+		if dryRun {
+			printMoveTasks(tasks, long)
+			return
+		}
+		if !yes {
+			printMoveTasks(tasks, long)
+			if !utility.DefaultScanComfirm(fmt.Sprintf("evacuate %d server(s) from %s", len(tasks), host)) {
+				return
+			}
+		}
+		results := runMoveTasks(client, tasks, opt)
+		printMoveTasks(results, long)
+		if lo.ContainsBy(results, func(t moveTask) bool { return t.Result == RESULT_FAILED }) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	registerMoveFlags(hostEvacuate)
+	hostEvacuate.Flags().String("password", "", "Set the provided admin password on the evacuated servers")
+	hostEvacuate.Flags().Bool("force", false, "Force to not verify the scheduler if a target host is provided")
+}
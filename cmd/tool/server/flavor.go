@@ -9,6 +9,7 @@ import (
 	"github.com/BytemanD/skyman/common"
 	"github.com/BytemanD/skyman/openstack/model/nova"
 	"github.com/BytemanD/skyman/utility"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
 
@@ -93,10 +94,23 @@ var flavorClone = &cobra.Command{
 			_, err = novaClient.SetFlavorExtraSpecs(newFlavor.Id, extraSpecs)
 			utility.LogError(err, "set new flavor extra specs failed", true)
 		}
+		if !flavor.IsPublic {
+			console.Info("copy flavor access")
+			err = novaClient.CopyFlavorAccess(flavorId, newFlavor.Id)
+			utility.LogError(err, "copy flavor access failed", true)
+		}
 
 		newFlavor, err = novaClient.GetFlavorWithExtraSpecs(newFlavor.Id)
 		utility.LogError(err, "show new flavor", true)
-		views.PrintFlavor(*newFlavor)
+		projects := []string{}
+		if !newFlavor.IsPublic {
+			accessList, err := novaClient.ListFlavorAccess(newFlavor.Id)
+			utility.LogError(err, "list new flavor access failed", true)
+			projects = lo.Map(accessList, func(access nova.FlavorAccess, _ int) string {
+				return access.TenantId
+			})
+		}
+		views.PrintFlavor(*newFlavor, projects...)
 	},
 }
 
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"sort"
 	"text/template"
 
 	"github.com/BytemanD/easygo/pkg/stringutils"
@@ -29,6 +30,15 @@ type ServerInspect struct {
 	InterfaceDetail map[string]neutron.Port    `json:"interfaceDetail"`
 	VolumeDetail    map[string]cinder.Volume   `json:"volumeDetail"`
 	Actions         []nova.InstanceAction      `json:"actions"`
+	Allocations     []ServerAllocation         `json:"allocations"`
+}
+
+// placement 中虚拟机在资源提供者上的分配
+type ServerAllocation struct {
+	Provider      string `json:"provider"`
+	ProviderName  string `json:"providerName"`
+	ResourceClass string `json:"resourceClass"`
+	Amount        int    `json:"amount"`
 }
 
 func (serverInspect *ServerInspect) Print() {
@@ -65,6 +75,13 @@ func (serverInspect *ServerInspect) Print() {
 	1. {{$key}} = {{$value}}
 {{end}}
 
+## Placement 分配
+
+| **Provider** | **Provider Name** | **Resource Class** | **Amount** |
+|---|---|---|---|
+{{ range $index, $alloc := .Allocations }} {{$alloc.Provider}} |{{$alloc.ProviderName}} {{ hostMismatch $alloc.ProviderName }} | {{ $alloc.ResourceClass }} |{{ $alloc.Amount }} |
+{{end}}
+
 ## 网卡
 {{ range $index, $interface := .Interfaces }}
 1. **PortId**: {{$interface.PortId}}
@@ -155,6 +172,14 @@ func (serverInspect *ServerInspect) Print() {
 		"humanRam": func(flavor nova.Flavor) string {
 			return flavor.HumanRam()
 		},
+		"hostMismatch": func(providerName string) string {
+			if providerName == "" || serverInspect.Server.Host == "" ||
+				providerName == serverInspect.Server.Host ||
+				providerName == serverInspect.Server.HypervisorHostname {
+				return ""
+			}
+			return fmt.Sprintf("(**host is %s**)", serverInspect.Server.Host)
+		},
 	})
 	tmpl, _ = tmpl.Parse(source)
 	tmpl.Execute(bufferWriter, serverInspect)
@@ -168,6 +193,32 @@ func (serverInspect *ServerInspect) Print() {
 	fmt.Println(string(result))
 }
 
+func getServerAllocations(client *openstack.Openstack, serverId string) ([]ServerAllocation, error) {
+	consumer, err := client.PlacementV1().GetAllocations(serverId)
+	if err != nil {
+		return nil, err
+	}
+	allocations := []ServerAllocation{}
+	for rpUuid, alloc := range consumer.Allocations {
+		rpName := ""
+		if rp, err := client.PlacementV1().GetResourceProvider(rpUuid); err == nil {
+			rpName = rp.Name
+		}
+		for rc, amount := range alloc.Resources {
+			allocations = append(allocations, ServerAllocation{
+				Provider: rpUuid, ProviderName: rpName, ResourceClass: rc, Amount: amount,
+			})
+		}
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		if allocations[i].Provider != allocations[j].Provider {
+			return allocations[i].Provider < allocations[j].Provider
+		}
+		return allocations[i].ResourceClass < allocations[j].ResourceClass
+	})
+	return allocations, nil
+}
+
 func inspect(client *openstack.Openstack, idOrName string) (*ServerInspect, error) {
 	server, err := client.NovaV2().FindServer(idOrName)
 	if err != nil {
@@ -218,6 +269,12 @@ func inspect(client *openstack.Openstack, idOrName string) (*ServerInspect, erro
 		utility.LogError(err, "get volume failed", true)
 		serverInspect.VolumeDetail[volume.VolumeId] = *vol
 	}
+	console.Info("get server allocations")
+	if allocations, err := getServerAllocations(client, server.Id); err != nil {
+		console.Warn("get server allocations failed: %s", err)
+	} else {
+		serverInspect.Allocations = allocations
+	}
 	return &serverInspect, nil
 }
 
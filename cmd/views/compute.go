@@ -106,7 +106,7 @@ func PrintServer(server nova.Server, client *openstack.Openstack) {
 	common.PrintPrettyItemTable(pt)
 }
 
-func PrintFlavor(server nova.Flavor) {
+func PrintFlavor(server nova.Flavor, projects ...string) {
 	pt := common.PrettyItemTable{
 		Item: server,
 		ShortFields: []common.Column{
@@ -136,6 +136,13 @@ func PrintFlavor(server nova.Flavor) {
 			}},
 		},
 	}
+	if !server.IsPublic {
+		pt.ShortFields = append(pt.ShortFields, common.Column{
+			Name: "Projects", Slot: func(item any) any {
+				return strings.Join(projects, "\n")
+			}},
+		)
+	}
 	common.PrintPrettyItemTable(pt)
 }
 
@@ -117,8 +117,6 @@ func (t DataTable[T]) renderItem(columns []Column[T], item T) table.Row {
 			value = column.RenderFunc(item)
 		case column.SlotColumn != nil:
 			value = column.SlotColumn(item, column)
-		case column.Text != "":
-			value = column.Text
 		default:
 			value = reflectValue.FieldByName(column.Name)
 		}
@@ -24,6 +24,9 @@ type InterfaceHotplug struct {
 type VolumeHotplug struct {
 	Nums int `yaml:"nums"`
 }
+type TrunkSubportHotplug struct {
+	Nums int `yaml:"nums"`
+}
 type QGAChecker struct {
 	Enabled             bool `yaml:"enabled"`
 	GuestConnectTimeout int  `yaml:"guestConnectTimeout"`
@@ -58,11 +61,12 @@ type CaseConfig struct {
 	VolumeType string `yaml:"volumeType"`
 	VolumeSize int    `yaml:"volumeSize"`
 
-	InterfaceHotplug InterfaceHotplug   `yaml:"interfaceHotplug"`
-	VolumeHotplug    VolumeHotplug      `yaml:"volumeHotplug"`
-	QGAChecker       QGAChecker         `yaml:"qgaChecker"`
-	LiveMigrate      LiveMigrateOptions `yaml:"liveMigrate"`
-	RevertSystem     RevertSystemConf   `yaml:"revertSystem"`
+	InterfaceHotplug    InterfaceHotplug    `yaml:"interfaceHotplug"`
+	VolumeHotplug       VolumeHotplug       `yaml:"volumeHotplug"`
+	TrunkSubportHotplug TrunkSubportHotplug `yaml:"trunkSubportHotplug"`
+	QGAChecker          QGAChecker          `yaml:"qgaChecker"`
+	LiveMigrate         LiveMigrateOptions  `yaml:"liveMigrate"`
+	RevertSystem        RevertSystemConf    `yaml:"revertSystem"`
 }
 type Case struct {
 	Name    string     `yaml:"name"`
@@ -119,6 +123,9 @@ func NewActionCaseConfig(config CaseConfig, def CaseConfig) CaseConfig {
 		VolumeHotplug: VolumeHotplug{
 			Nums: lo.CoalesceOrEmpty(config.VolumeHotplug.Nums, def.VolumeHotplug.Nums, 1),
 		},
+		TrunkSubportHotplug: TrunkSubportHotplug{
+			Nums: lo.CoalesceOrEmpty(config.TrunkSubportHotplug.Nums, def.TrunkSubportHotplug.Nums, 1),
+		},
 		QGAChecker: QGAChecker{
 			Enabled: lo.CoalesceOrEmpty(config.QGAChecker.Enabled, def.QGAChecker.Enabled),
 			GuestConnectTimeout: lo.CoalesceOrEmpty(config.QGAChecker.GuestConnectTimeout, def.QGAChecker.GuestConnectTimeout,
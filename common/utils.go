@@ -7,8 +7,10 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BytemanD/easygo/pkg/stringutils"
+	"github.com/BytemanD/skyman/openstack"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -37,6 +39,19 @@ func SplitKeyValue(kv string) ([]string, error) {
 	}
 }
 
+// 注册 --wait 和 --wait-timeout 参数
+func RegistryWaitFlags(cmd ...*cobra.Command) {
+	for _, c := range cmd {
+		c.Flags().BoolP("wait", "w", false, "Wait for the operation to complete")
+		c.Flags().Duration("wait-timeout", openstack.DEFAULT_WAIT_TIMEOUT, "Timeout of waiting, 0 means no timeout")
+	}
+}
+func GetWaitFlags(cmd *cobra.Command) (bool, time.Duration) {
+	wait, _ := cmd.Flags().GetBool("wait")
+	timeout, _ := cmd.Flags().GetDuration("wait-timeout")
+	return wait, timeout
+}
+
 func RegistryLongFlag(cmd ...*cobra.Command) {
 	for _, c := range cmd {
 		c.Flags().BoolP("long", "l", false, "List additional fields in output")
@@ -209,7 +209,17 @@ func PrintSubnet(item neutron.Subnet) {
 			{Name: "AllocationPools", RenderFunc: func(item neutron.Subnet) any {
 				return strings.Join(item.GetAllocationPoolsList(), ",")
 			}},
-			{Name: "GatewayIp"}, {Name: "RevisionNumber"}, {Name: "HostRouters"},
+			{Name: "GatewayIp"},
+			{Name: "DnsNameservers", RenderFunc: func(item neutron.Subnet) any {
+				return strings.Join(item.DnsNameservers, "\n")
+			}},
+			{Name: "HostRouters", Text: "Host Routes", RenderFunc: func(item neutron.Subnet) any {
+				return strings.Join(item.GetHostRoutesList(), "\n")
+			}},
+			{Name: "Ipv6RaMode", Text: "IPv6 RA Mode"},
+			{Name: "Ipv6AddressMode", Text: "IPv6 Address Mode"},
+			{Name: "SubnetPoolId"},
+			{Name: "RevisionNumber"},
 			{Name: "Tags"},
 			{Name: "ProjectId"},
 			{Name: "UpdatedAt"}, {Name: "CreatedAt"},
@@ -227,7 +237,12 @@ func PrintRouters(items []neutron.Router, long bool) {
 			{Name: "HA", Text: "HA"},
 		},
 		[]datatable.Column[neutron.Router]{
-			{Name: "Routes"}, {Name: "ExternalGatewayinfo"},
+			{Name: "Routes", RenderFunc: func(item neutron.Router) any {
+				return strings.Join(item.RouteStrings(), "\n")
+			}},
+			{Name: "GatewayIps", RenderFunc: func(item neutron.Router) any {
+				return strings.Join(item.GatewayIps(), "\n")
+			}},
 		},
 		items, TableOptions{
 			SortBy: []table.SortBy{{Name: "Name"}},
@@ -246,7 +261,10 @@ func PrintRouter(item neutron.Router) {
 			{Name: "ExternalGatewayInfo", RenderFunc: func(item neutron.Router) any {
 				return item.MarshalExternalGatewayInfo()
 			}},
-			{Name: "HA", Text: "Ha"},
+			{Name: "HA", Text: "HA"},
+			{Name: "Routes", RenderFunc: func(item neutron.Router) any {
+				return strings.Join(item.RouteStrings(), "\n")
+			}},
 			{Name: "Status"},
 			{Name: "Tags"},
 			{Name: "ProjectId"},
@@ -313,6 +331,19 @@ func PrintPort(item neutron.Port) {
 			{Name: "FixedIps"},
 			{Name: "DeviceOwner"}, {Name: "DeviceId"},
 			{Name: "QosPolicyId"}, {Name: "SecurityGroups"},
+			{Name: "PortSecurityEnabled", RenderFunc: func(item neutron.Port) any {
+				if item.PortSecurityEnabled == nil {
+					return ""
+				}
+				return *item.PortSecurityEnabled
+			}},
+			{Name: "AllowedAddressPairs", RenderFunc: func(item neutron.Port) any {
+				pairs := []string{}
+				for _, pair := range item.AllowedAddressPairs {
+					pairs = append(pairs, pair.String())
+				}
+				return strings.Join(pairs, "\n")
+			}},
 			{Name: "RevsionNumber"},
 			{Name: "ProjectId"},
 			{Name: "CreatedAt"}, {Name: "UpdatedAt"},
@@ -443,6 +474,7 @@ func PrintQosPolicyRules(items []neutron.QosRule, long bool) {
 			{Name: "MaxKbps"},
 			{Name: "MaxBurstKbps"},
 			{Name: "MinKbps"},
+			{Name: "DscpMark"},
 		},
 		[]datatable.Column[neutron.QosRule]{},
 		items, TableOptions{More: long},
@@ -452,16 +484,53 @@ func PrintQosPolicyRules(items []neutron.QosRule, long bool) {
 func PrintQosPolicyRule(item neutron.QosRule) {
 	PrintItem(
 		[]datatable.Field[neutron.QosRule]{
+			{Name: "Id"}, {Name: "QosPolicyId"},
+			{Name: "Type"},
+			{Name: "Direction"},
+			{Name: "MaxKbps"},
+			{Name: "MaxBurstKbps"},
+			{Name: "MinKbps"},
+			{Name: "DscpMark"},
+		},
+		[]datatable.Field[neutron.QosRule]{},
+		item, TableOptions{},
+	)
+}
+
+func PrintTrunks(items []neutron.Trunk, long bool) {
+	PrintItems(
+		[]datatable.Column[neutron.Trunk]{
 			{Name: "Id"}, {Name: "Name"},
-			{Name: "Description"},
-			{Name: "Shared"},
-			{Name: "ProjectId", Text: "Project"},
-			{Name: "Rules", RenderFunc: func(item neutron.QosRule) any {
-				bytes, _ := json.Marshal(item)
-				return string(bytes)
+			{Name: "PortId", Text: "Parent Port"},
+			{Name: "Status", AutoColor: true},
+			{Name: "SubPorts", RenderFunc: func(item neutron.Trunk) any {
+				return len(item.SubPorts)
 			}},
 		},
-		[]datatable.Field[neutron.QosRule]{},
+		[]datatable.Column[neutron.Trunk]{
+			{Name: "AdminStateUp"}, {Name: "ProjectId", Text: "Project"},
+		},
+		items, TableOptions{More: long},
+	)
+}
+func PrintTrunk(item neutron.Trunk) {
+	PrintItem(
+		[]datatable.Field[neutron.Trunk]{
+			{Name: "Id"}, {Name: "Name"}, {Name: "Description"},
+			{Name: "PortId", Text: "Parent Port"},
+			{Name: "Status"}, {Name: "AdminStateUp"},
+			{Name: "SubPorts", RenderFunc: func(item neutron.Trunk) any {
+				subPorts := []string{}
+				for _, subPort := range item.SubPorts {
+					subPorts = append(subPorts, subPort.String())
+				}
+				return strings.Join(subPorts, "\n")
+			}},
+			{Name: "RevisionNumber"},
+			{Name: "ProjectId", Text: "Project"},
+			{Name: "CreatedAt"}, {Name: "UpdatedAt"},
+		},
+		[]datatable.Field[neutron.Trunk]{},
 		item, TableOptions{},
 	)
 }
@@ -478,11 +547,36 @@ func PrintAgents(items []neutron.Agent, long bool) {
 			{Name: "AdminStateUp"},
 			{Name: "Binary"},
 		},
-		[]datatable.Column[neutron.Agent]{},
+		[]datatable.Column[neutron.Agent]{
+			{Name: "HaState", AutoColor: true},
+		},
 		items, TableOptions{
 			More: long},
 	)
 }
+func PrintAgent(item neutron.Agent) {
+	PrintItem(
+		[]datatable.Field[neutron.Agent]{
+			{Name: "Id"}, {Name: "AgentType"},
+			{Name: "Binary"}, {Name: "Host"},
+			{Name: "Topic"},
+			{Name: "AvailabilityZone"},
+			{Name: "Alive", RenderFunc: func(item neutron.Agent) any {
+				return item.AliveEmoji()
+			}},
+			{Name: "AdminStateUp"},
+			{Name: "Description"},
+			{Name: "Configurations", RenderFunc: func(item neutron.Agent) any {
+				return item.MarshalConfigurations()
+			}},
+			{Name: "HeartbeatTimestamp"},
+			{Name: "StartedAt"},
+			{Name: "CreatedAt"},
+		},
+		[]datatable.Field[neutron.Agent]{},
+		item, TableOptions{},
+	)
+}
 
 // glance
 
@@ -536,28 +630,6 @@ func PrintImage(item glance.Image, human bool) {
 	)
 }
 
-// quota
-
-func PrintQuotaSet(item nova.QuotaSet, more bool) {
-	PrintItem(
-		[]datatable.Field[nova.QuotaSet]{
-			{Name: "Instances"},
-			{Name: "Cores"}, {Name: "Ram"},
-			{Name: "MetadataItems"},
-			{Name: "SecurityGroups"},
-			{Name: "SecurityGroupsMembers"},
-			{Name: "InjectedFiles"},
-			{Name: "InjectedFileContentBytes"},
-			{Name: "InjectedFilePathBytes"},
-		},
-		[]datatable.Field[nova.QuotaSet]{
-			{Name: "FloatingIps"},
-			{Name: "FixedIps"},
-		},
-		item, TableOptions{More: more},
-	)
-}
-
 // keystone
 func PrintRegions(items []keystone.Region, long bool) {
 	PrintItems(
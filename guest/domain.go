@@ -0,0 +1,84 @@
+package guest
+
+import (
+	"fmt"
+
+	"libvirt.org/go/libvirt"
+)
+
+// 主机上的 libvirt 域
+type Domain struct {
+	Name  string
+	UUID  string
+	State libvirt.DomainState
+}
+
+var domainStateNames = map[libvirt.DomainState]string{
+	libvirt.DOMAIN_NOSTATE:     "nostate",
+	libvirt.DOMAIN_RUNNING:     "running",
+	libvirt.DOMAIN_BLOCKED:     "blocked",
+	libvirt.DOMAIN_PAUSED:      "paused",
+	libvirt.DOMAIN_SHUTDOWN:    "shutdown",
+	libvirt.DOMAIN_SHUTOFF:     "shutoff",
+	libvirt.DOMAIN_CRASHED:     "crashed",
+	libvirt.DOMAIN_PMSUSPENDED: "pmsuspended",
+}
+
+// nova power_state 取值, 与 nova.virt.libvirt 中的映射保持一致
+var domainPowerStates = map[libvirt.DomainState]int{
+	libvirt.DOMAIN_NOSTATE:     0,
+	libvirt.DOMAIN_RUNNING:     1,
+	libvirt.DOMAIN_BLOCKED:     1,
+	libvirt.DOMAIN_PAUSED:      3,
+	libvirt.DOMAIN_SHUTDOWN:    4,
+	libvirt.DOMAIN_SHUTOFF:     4,
+	libvirt.DOMAIN_CRASHED:     6,
+	libvirt.DOMAIN_PMSUSPENDED: 7,
+}
+
+func (d Domain) StateName() string {
+	if name, ok := domainStateNames[d.State]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", d.State)
+}
+func (d Domain) PowerState() int {
+	return domainPowerStates[d.State]
+}
+
+func connect(connection string) (*libvirt.Connect, error) {
+	return libvirt.NewConnect(fmt.Sprintf("qemu+tcp://%s/system", connection))
+}
+
+// 获取主机上所有的域, 包括未运行的域
+func ListDomains(connection string) ([]Domain, error) {
+	conn, err := connect(connection)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	domains, err := conn.ListAllDomains(0)
+	if err != nil {
+		return nil, err
+	}
+	result := []Domain{}
+	for _, domain := range domains {
+		item := Domain{}
+		if item.Name, err = domain.GetName(); err != nil {
+			domain.Free()
+			return nil, err
+		}
+		if item.UUID, err = domain.GetUUIDString(); err != nil {
+			domain.Free()
+			return nil, err
+		}
+		if item.State, _, err = domain.GetState(); err != nil {
+			domain.Free()
+			return nil, err
+		}
+		domain.Free()
+		result = append(result, item)
+	}
+	return result, nil
+}
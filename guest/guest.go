@@ -31,7 +31,7 @@ type FioOptions struct {
 
 func (guest *Guest) Connect() error {
 	console.Debug("connecting to %s ...", guest)
-	conn, err := libvirt.NewConnect(fmt.Sprintf("qemu+tcp://%s/system", guest.Connection))
+	conn, err := connect(guest.Connection)
 	if err != nil {
 		return err
 	}
@@ -32,6 +32,7 @@ const (
 	STORAGE   = "storage"
 	COMPUTE   = "compute"
 	IMAGE     = "image"
+	PLACEMENT = "placement"
 
 	KEYSTONE  = "keystone"
 	NOVA      = "nova"
@@ -56,11 +57,12 @@ type Openstack struct {
 
 	servieLock *sync.Mutex
 
-	novaClient     *internal.NovaV2
-	keystoneClient *internal.KeystoneV3
-	glanceClient   *internal.GlanceV2
-	cinderClient   *internal.CinderV2
-	neutronClient  *internal.NeutronV2
+	novaClient      *internal.NovaV2
+	keystoneClient  *internal.KeystoneV3
+	glanceClient    *internal.GlanceV2
+	cinderClient    *internal.CinderV2
+	neutronClient   *internal.NeutronV2
+	placementClient *internal.PlacementV1
 
 	novaClientOnce   *sync.Once
 	cinderClientOnce *sync.Once
@@ -80,6 +82,7 @@ func (o *Openstack) ResetAllClients() {
 	o.cinderClient = nil
 	o.novaClient = nil
 	o.neutronClient = nil
+	o.placementClient = nil
 }
 
 func (o *Openstack) SetRegion(region string) *Openstack {
@@ -160,6 +163,20 @@ func (o *Openstack) NeutronV2() *internal.NeutronV2 {
 	}
 	return o.neutronClient
 }
+func (o *Openstack) PlacementV1() *internal.PlacementV1 {
+	o.servieLock.Lock()
+	defer o.servieLock.Unlock()
+
+	if o.placementClient == nil {
+		o.placementClient = &internal.PlacementV1{
+			ServiceClient: internal.NewServiceClient(
+				o.Region(), PLACEMENT, PLACEMENT, PUBLIC, "", o.AuthPlugin,
+			),
+		}
+		o.placementClient.SetMicroVersion(internal.PLACEMENT_DEFAULT_MICRO_VERSION)
+	}
+	return o.placementClient
+}
 func (o *Openstack) KeystoneV3() *internal.KeystoneV3 {
 	o.servieLock.Lock()
 	defer o.servieLock.Unlock()
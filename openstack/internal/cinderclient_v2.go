@@ -174,7 +174,7 @@ func (c CinderV2) ListBackup(query url.Values, details ...bool) ([]cinder.Backup
 
 }
 func (c CinderV2) GetBackup(id string) (*cinder.Backup, error) {
-	return GetResource[cinder.Backup](c.ServiceClient, URL_VOLUME.F(id), "backup")
+	return GetResource[cinder.Backup](c.ServiceClient, URL_BACKUP.F(id), "backup")
 }
 func (c CinderV2) DeleteBackup(id string) error {
 	return DeleteResource(c.ServiceClient, URL_BACKUP.F(id))
@@ -204,6 +204,28 @@ func (c CinderV2) CreateBackup(volumeId string, name string, force bool) (*cinde
 	return &result.Backup, err
 }
 
+// quota api
+
+func (c CinderV2) GetQuotaLimits(projectId string) (map[string]int, error) {
+	return GetQuota[int](c.ServiceClient, URL_QUOTA_DETAIL.F(projectId), nil, "quota_set")
+}
+func (c CinderV2) GetQuotaUsages(projectId string) (map[string]cinder.QuotaUsage, error) {
+	return GetQuota[cinder.QuotaUsage](c.ServiceClient, URL_QUOTA_DETAIL.F(projectId),
+		url.Values{"usage": []string{"true"}}, "quota_set")
+}
+func (c CinderV2) GetDefaultQuotaLimits(projectId string) (map[string]int, error) {
+	return GetQuota[int](c.ServiceClient, URL_QUOTA_DEFAULTS.F(projectId), nil, "quota_set")
+}
+func (c CinderV2) UpdateQuotaLimits(projectId string, limits map[string]int) (map[string]int, error) {
+	return UpdateQuota(c.ServiceClient, URL_QUOTA_DETAIL.F(projectId), "quota_set", limits)
+}
+func (c CinderV2) GetQuotaClassLimits(class string) (map[string]int, error) {
+	return GetQuota[int](c.ServiceClient, URL_QUOTA_CLASS.F(class), nil, "quota_class_set")
+}
+func (c CinderV2) UpdateQuotaClassLimits(class string, limits map[string]int) (map[string]int, error) {
+	return UpdateQuota(c.ServiceClient, URL_QUOTA_CLASS.F(class), "quota_class_set", limits)
+}
+
 func (c CinderV2) GetCurrentVersion() (*model.ApiVersion, error) {
 	result := struct{ Versions model.ApiVersions }{}
 	if _, err := c.Index(&result); err != nil {
@@ -223,21 +245,50 @@ func (c CinderV2) CreateVolumeAndWait(options map[string]any, timeoutSeconds int
 	if err != nil {
 		return nil, err
 	}
-	startTime := time.Now()
-	for {
-		vol, err := c.GetVolume(volume.Id)
-		if err != nil {
-			return volume, fmt.Errorf("show volume failed: %s", err)
-		}
-		if vol.IsError() {
-			return volume, fmt.Errorf("status is error")
-		}
-		if vol.IsAvailable() {
-			return volume, nil
-		}
-		if time.Since(startTime) >= time.Second*time.Duration(timeoutSeconds) {
-			return volume, fmt.Errorf("create timeout")
-		}
-		time.Sleep(time.Second * 2)
+	if _, err := c.WaitVolumeStatus(volume.Id, "available", time.Second*time.Duration(timeoutSeconds)); err != nil {
+		return volume, err
 	}
+	return volume, nil
+}
+
+func (c CinderV2) VolumeWaiter(id string, status string) *Waiter[cinder.Volume] {
+	return newStatusWaiter(fmt.Sprintf("volume %s", id),
+		func() (*cinder.Volume, error) { return c.GetVolume(id) },
+		func(v *cinder.Volume) string { return v.Status },
+		status, "error", "error_deleting", "error_extending", "error_restoring")
+}
+func (c CinderV2) WaitVolumeStatus(id string, status string, timeout time.Duration) (*cinder.Volume, error) {
+	return c.VolumeWaiter(id, status).WithTimeout(timeout).Wait()
+}
+func (c CinderV2) WaitVolumeDeleted(id string, timeout time.Duration) error {
+	_, err := c.VolumeWaiter(id, "").UntilDeleted().WithTimeout(timeout).Wait()
+	return err
+}
+
+func (c CinderV2) SnapshotWaiter(id string, status string) *Waiter[cinder.Snapshot] {
+	return newStatusWaiter(fmt.Sprintf("snapshot %s", id),
+		func() (*cinder.Snapshot, error) { return c.GetSnapshot(id) },
+		func(s *cinder.Snapshot) string { return s.Status },
+		status, "error", "error_deleting")
+}
+func (c CinderV2) WaitSnapshotStatus(id string, status string, timeout time.Duration) (*cinder.Snapshot, error) {
+	return c.SnapshotWaiter(id, status).WithTimeout(timeout).Wait()
+}
+func (c CinderV2) WaitSnapshotDeleted(id string, timeout time.Duration) error {
+	_, err := c.SnapshotWaiter(id, "").UntilDeleted().WithTimeout(timeout).Wait()
+	return err
+}
+
+func (c CinderV2) BackupWaiter(id string, status string) *Waiter[cinder.Backup] {
+	return newStatusWaiter(fmt.Sprintf("backup %s", id),
+		func() (*cinder.Backup, error) { return c.GetBackup(id) },
+		func(b *cinder.Backup) string { return b.Status },
+		status, "error", "error_deleting", "error_restoring")
+}
+func (c CinderV2) WaitBackupStatus(id string, status string, timeout time.Duration) (*cinder.Backup, error) {
+	return c.BackupWaiter(id, status).WithTimeout(timeout).Wait()
+}
+func (c CinderV2) WaitBackupDeleted(id string, timeout time.Duration) error {
+	_, err := c.BackupWaiter(id, "").UntilDeleted().WithTimeout(timeout).Wait()
+	return err
 }
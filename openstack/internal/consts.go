@@ -39,6 +39,7 @@ const (
 	URL_HYPERVISOR_UPTIME      UrlPath = "os-hypervisors/%s/uptime"
 	URL_HYPERVISOR_CAPACITIES  UrlPath = "os-hypervisors/statistics/flavor-capacities"
 	URL_SERVER_ACTION          UrlPath = "servers/%s/action"
+	URL_SERVER_PASSWORD        UrlPath = "servers/%s/os-server-password"
 	// 虚拟机接口
 	URL_SERVER_INTERFACES UrlPath = "servers/%s/os-interface"
 	URL_SERVER_INTERFACE  UrlPath = "servers/%s/os-interface/%s"
@@ -56,18 +57,26 @@ const (
 	URL_AGGREGATE         UrlPath = "os-aggregates/%s"
 	URL_AGGREGATE_ACTION  UrlPath = "os-aggregates/%s/action"
 	URL_AGGREGATES_DETAIL UrlPath = "os-aggregates/detail"
+	URL_AGGREGATE_IMAGES  UrlPath = "os-aggregates/%s/images"
 	// 虚拟机迁移
 	URL_MIGRATIONS_LIST UrlPath = "os-migrations"
 	// 配额
 	URL_QUOTAS_LIST    UrlPath = "os-quota-sets"
 	URL_QUOTA_DETAIL   UrlPath = "os-quota-sets/%s"
 	URL_QUOTA_DEFAULTS UrlPath = "os-quota-sets/%s/defaults"
+	URL_QUOTA_USAGE    UrlPath = "os-quota-sets/%s/detail"
+	URL_QUOTA_CLASS    UrlPath = "os-quota-class-sets/%s"
+
+	URL_TENANT_USAGES UrlPath = "os-simple-tenant-usage"
+	URL_TENANT_USAGE  UrlPath = "os-simple-tenant-usage/%s"
 	// 虚拟机类型
 	URL_FLAVORS            UrlPath = "flavors"
 	URL_FLAVORS_DETAIL     UrlPath = "flavors/detail"
 	URL_FLAVOR             UrlPath = "flavors/%s"
 	URL_FLAVOR_EXTRA_SPECS UrlPath = "flavors/%s/os-extra_specs"
 	URL_FLAVOR_EXTRA_SPEC  UrlPath = "flavors/%s/os-extra_specs/%s"
+	URL_FLAVOR_ACCESS      UrlPath = "flavors/%s/os-flavor-access"
+	URL_FLAVOR_ACTION      UrlPath = "flavors/%s/action"
 	// 虚拟机卷
 	URL_SERVER_VOLUMES UrlPath = "servers/%s/os-volume_attachments"
 	URL_SERVER_VOLUME  UrlPath = "servers/%s/os-volume_attachments/%s"
@@ -132,7 +141,14 @@ const (
 
 	// neutron
 
-	URL_AGENTS UrlPath = "agents"
+	URL_AGENTS              UrlPath = "agents"
+	URL_AGENT               UrlPath = "agents/%s"
+	URL_AGENT_DHCP_NETWORKS UrlPath = "agents/%s/dhcp-networks"
+	URL_AGENT_DHCP_NETWORK  UrlPath = "agents/%s/dhcp-networks/%s"
+	URL_AGENT_L3_ROUTERS    UrlPath = "agents/%s/l3-routers"
+	URL_AGENT_L3_ROUTER     UrlPath = "agents/%s/l3-routers/%s"
+	URL_NETWORK_DHCP_AGENTS UrlPath = "networks/%s/dhcp-agents"
+	URL_ROUTER_L3_AGENTS    UrlPath = "routers/%s/l3-agents"
 
 	URL_NETWORKS UrlPath = "networks"
 	URL_NETWORK  UrlPath = "networks/%s"
@@ -140,13 +156,21 @@ const (
 	URL_SUBNETS UrlPath = "subnets"
 	URL_SUBNET  UrlPath = "subnets/%s"
 
+	URL_SUBNET_POOLS UrlPath = "subnetpools"
+	URL_SUBNET_POOL  UrlPath = "subnetpools/%s"
+
+	URL_NETWORK_IP_AVAILABILITIES UrlPath = "network-ip-availabilities"
+	URL_NETWORK_IP_AVAILABILITY   UrlPath = "network-ip-availabilities/%s"
+
 	URL_PORTS UrlPath = "ports"
 	URL_PORT  UrlPath = "ports/%s"
 
-	URL_ROUTERS                 UrlPath = "routers"
-	URL_ROUTER                  UrlPath = "routers/%s"
-	URL_ROUTER_ADD_INTERFACE    UrlPath = "routers/%s/add_router_interface"
-	URL_ROUTER_REMOVE_INTERFACE UrlPath = "routers/%s/remove_router_interface"
+	URL_ROUTERS                   UrlPath = "routers"
+	URL_ROUTER                    UrlPath = "routers/%s"
+	URL_ROUTER_ADD_INTERFACE      UrlPath = "routers/%s/add_router_interface"
+	URL_ROUTER_REMOVE_INTERFACE   UrlPath = "routers/%s/remove_router_interface"
+	URL_ROUTER_ADD_EXTRAROUTES    UrlPath = "routers/%s/add_extraroutes"
+	URL_ROUTER_REMOVE_EXTRAROUTES UrlPath = "routers/%s/remove_extraroutes"
 
 	URL_FLOATINGIPS UrlPath = "floatingips"
 	URL_FLOATINGIP  UrlPath = "floatingips/%s"
@@ -174,9 +198,30 @@ const (
 	URL_FIREWALL_RULES    UrlPath = "fw/firewall_rules"
 	URL_FIREWALL_RULE     UrlPath = "fw/firewall_rules/%s"
 
+	URL_NEUTRON_QUOTA         UrlPath = "quotas/%s"
+	URL_NEUTRON_QUOTA_DETAIL  UrlPath = "quotas/%s/details"
+	URL_NEUTRON_QUOTA_DEFAULT UrlPath = "quotas/%s/default"
+
 	URL_QOS_POLICIES     UrlPath = "qos/policies"
 	URL_QOS_POLICY       UrlPath = "qos/policies/%s"
-	URL_QOS_POLICY_RULES UrlPath = "qos/policies-rules"
+	URL_QOS_POLICY_RULES UrlPath = "qos/policies/%s/%s_rules"
+	URL_QOS_POLICY_RULE  UrlPath = "qos/policies/%s/%s_rules/%s"
+
+	URL_TRUNKS                UrlPath = "trunks"
+	URL_TRUNK                 UrlPath = "trunks/%s"
+	URL_TRUNK_ADD_SUBPORTS    UrlPath = "trunks/%s/add_subports"
+	URL_TRUNK_REMOVE_SUBPORTS UrlPath = "trunks/%s/remove_subports"
+
+	// placement
+	URL_RESOURCE_PROVIDERS            UrlPath = "resource_providers"
+	URL_RESOURCE_PROVIDER             UrlPath = "resource_providers/%s"
+	URL_RESOURCE_PROVIDER_INVENTORIES UrlPath = "resource_providers/%s/inventories"
+	URL_RESOURCE_PROVIDER_USAGES      UrlPath = "resource_providers/%s/usages"
+	URL_RESOURCE_PROVIDER_ALLOCATIONS UrlPath = "resource_providers/%s/allocations"
+	URL_RESOURCE_PROVIDER_TRAITS      UrlPath = "resource_providers/%s/traits"
+	URL_RESOURCE_PROVIDER_AGGREGATES  UrlPath = "resource_providers/%s/aggregates"
+	URL_ALLOCATION                    UrlPath = "allocations/%s"
+	URL_ALLOCATION_CANDIDATES         UrlPath = "allocation_candidates"
 )
 
 // response body key
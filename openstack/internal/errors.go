@@ -12,3 +12,8 @@ var ErrServerNotStopped = errors.New("server is not stopped")
 var ErrServerIsNotDeleted = errors.New("server is not deleted")
 var ErrServerIsError = errors.New("server is error")
 var ErrServerStatusNotExpect = errors.New("server status is not expect")
+
+var ErrMicroVersionNotSupport = errors.New("micro version not support")
+
+var ErrWaitTimeout = errors.New("wait timeout")
+var ErrResourceIsError = errors.New("resource is error")
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/openstack/model"
@@ -184,3 +185,17 @@ func (c GlanceV2) GetCurrentVersion() (*model.ApiVersion, error) {
 	}
 	return &result.Versions[0], nil
 }
+
+func (c GlanceV2) ImageWaiter(id string, status string) *Waiter[glance.Image] {
+	return newStatusWaiter(fmt.Sprintf("image %s", id),
+		func() (*glance.Image, error) { return c.GetImage(id) },
+		func(img *glance.Image) string { return img.Status },
+		status, "killed", "deleted")
+}
+func (c GlanceV2) WaitImageStatus(id string, status string, timeout time.Duration) (*glance.Image, error) {
+	return c.ImageWaiter(id, status).WithTimeout(timeout).Wait()
+}
+func (c GlanceV2) WaitImageDeleted(id string, timeout time.Duration) error {
+	_, err := c.ImageWaiter(id, "").FailWhen(nil).UntilDeleted().WithTimeout(timeout).Wait()
+	return err
+}
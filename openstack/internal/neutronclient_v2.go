@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/BytemanD/skyman/openstack/model"
 	"github.com/BytemanD/skyman/openstack/model/neutron"
@@ -50,6 +51,38 @@ func (c NeutronV2) CreateRouter(params map[string]any) (*neutron.Router, error)
 	}
 	return &result.Router, nil
 }
+func (c NeutronV2) UpdateRouter(id string, params map[string]any) (*neutron.Router, error) {
+	result := struct {
+		Router neutron.Router `json:"router"`
+	}{}
+	if _, err := c.R().SetBody(ReqBody{"router": params}).SetResult(&result).
+		Put(URL_ROUTER.F(id)); err != nil {
+		return nil, err
+	}
+	return &result.Router, nil
+}
+
+// 增量添加/删除静态路由, 不会覆盖已有的路由
+func (c NeutronV2) AddRouterExtraRoutes(id string, routes []neutron.Route) (*neutron.Router, error) {
+	result := struct {
+		Router neutron.Router `json:"router"`
+	}{}
+	if _, err := c.R().SetBody(ReqBody{"router": map[string]any{"routes": routes}}).SetResult(&result).
+		Put(URL_ROUTER_ADD_EXTRAROUTES.F(id)); err != nil {
+		return nil, err
+	}
+	return &result.Router, nil
+}
+func (c NeutronV2) RemoveRouterExtraRoutes(id string, routes []neutron.Route) (*neutron.Router, error) {
+	result := struct {
+		Router neutron.Router `json:"router"`
+	}{}
+	if _, err := c.R().SetBody(ReqBody{"router": map[string]any{"routes": routes}}).SetResult(&result).
+		Put(URL_ROUTER_REMOVE_EXTRAROUTES.F(id)); err != nil {
+		return nil, err
+	}
+	return &result.Router, nil
+}
 
 func (c NeutronV2) DeleteRouter(id string) error {
 	return DeleteResource(c.ServiceClient, URL_ROUTER.F(id))
@@ -111,6 +144,15 @@ func (c NeutronV2) CreateNetwork(params map[string]any) (*neutron.Network, error
 	return &body.Network, nil
 }
 
+func (c NeutronV2) UpdateNetwork(id string, params map[string]any) (*neutron.Network, error) {
+	body := struct{ Network neutron.Network }{}
+	if _, err := c.R().SetBody(map[string]any{"network": params}).
+		SetResult(&body).Put(URL_NETWORK.F(id)); err != nil {
+		return nil, err
+	}
+	return &body.Network, nil
+}
+
 func (c NeutronV2) DeleteNetwork(id string) error {
 	return DeleteResource(c.ServiceClient, URL_NETWORK.F(id))
 }
@@ -138,10 +180,41 @@ func (c NeutronV2) CreateSubnet(params map[string]any) (*neutron.Subnet, error)
 	}
 	return &body.Subnet, nil
 }
+func (c NeutronV2) UpdateSubnet(id string, params map[string]any) (*neutron.Subnet, error) {
+	body := struct{ Subnet neutron.Subnet }{}
+	if _, err := c.R().SetBody(map[string]any{"subnet": params}).SetResult(&body).
+		Put(URL_SUBNET.F(id)); err != nil {
+		return nil, err
+	}
+	return &body.Subnet, nil
+}
 func (c NeutronV2) DeleteSubnet(id string) error {
 	return DeleteResource(c.ServiceClient, URL_SUBNET.F(id))
 }
 
+// subnet pool api
+
+func (c NeutronV2) ListSubnetPool(query url.Values) ([]neutron.SubnetPool, error) {
+	return QueryResource[neutron.SubnetPool](c.ServiceClient, URL_SUBNET_POOLS.F(), query, "subnetpools")
+}
+func (c NeutronV2) GetSubnetPool(id string) (*neutron.SubnetPool, error) {
+	return GetResource[neutron.SubnetPool](c.ServiceClient, URL_SUBNET_POOL.F(id), "subnetpool")
+}
+func (c NeutronV2) FindSubnetPool(idOrName string) (*neutron.SubnetPool, error) {
+	return QueryByIdOrName(idOrName, c.GetSubnetPool, c.ListSubnetPool)
+}
+
+// network ip availability api
+
+func (c NeutronV2) ListNetworkIpAvailability(query url.Values) ([]neutron.NetworkIpAvailability, error) {
+	return QueryResource[neutron.NetworkIpAvailability](
+		c.ServiceClient, URL_NETWORK_IP_AVAILABILITIES.F(), query, "network_ip_availabilities")
+}
+func (c NeutronV2) GetNetworkIpAvailability(networkId string) (*neutron.NetworkIpAvailability, error) {
+	return GetResource[neutron.NetworkIpAvailability](
+		c.ServiceClient, URL_NETWORK_IP_AVAILABILITY.F(networkId), "network_ip_availability")
+}
+
 // port api
 
 func (c NeutronV2) ListPort(query url.Values) ([]neutron.Port, error) {
@@ -185,6 +258,58 @@ func (c NeutronV2) FindPort(idOrName string) (*neutron.Port, error) {
 func (c NeutronV2) ListAgent(query url.Values) ([]neutron.Agent, error) {
 	return QueryResource[neutron.Agent](c.ServiceClient, URL_AGENTS.F(), query, "agents")
 }
+func (c NeutronV2) GetAgent(id string) (*neutron.Agent, error) {
+	return GetResource[neutron.Agent](c.ServiceClient, URL_AGENT.F(id), "agent")
+}
+func (c NeutronV2) UpdateAgent(id string, params map[string]any) (*neutron.Agent, error) {
+	body := struct {
+		Agent neutron.Agent `json:"agent"`
+	}{}
+	if _, err := c.R().SetBody(map[string]any{"agent": params}).SetResult(&body).
+		Put(URL_AGENT.F(id)); err != nil {
+		return nil, err
+	}
+	return &body.Agent, nil
+}
+func (c NeutronV2) DeleteAgent(id string) error {
+	return DeleteResource(c.ServiceClient, URL_AGENT.F(id))
+}
+
+// DHCP agent 调度
+
+func (c NeutronV2) ListAgentNetworks(agentId string) ([]neutron.Network, error) {
+	return QueryResource[neutron.Network](c.ServiceClient, URL_AGENT_DHCP_NETWORKS.F(agentId), nil, NETWORKS)
+}
+func (c NeutronV2) AddAgentNetwork(agentId string, networkId string) error {
+	_, err := c.R().SetBody(map[string]string{"network_id": networkId}).
+		Post(URL_AGENT_DHCP_NETWORKS.F(agentId))
+	return err
+}
+func (c NeutronV2) RemoveAgentNetwork(agentId string, networkId string) error {
+	return DeleteResource(c.ServiceClient, URL_AGENT_DHCP_NETWORK.F(agentId, networkId))
+}
+func (c NeutronV2) ListNetworkAgents(networkId string) ([]neutron.Agent, error) {
+	return QueryResource[neutron.Agent](c.ServiceClient, URL_NETWORK_DHCP_AGENTS.F(networkId), nil, "agents")
+}
+
+// L3 agent 调度
+
+func (c NeutronV2) ListAgentRouters(agentId string) ([]neutron.Router, error) {
+	return QueryResource[neutron.Router](c.ServiceClient, URL_AGENT_L3_ROUTERS.F(agentId), nil, "routers")
+}
+func (c NeutronV2) AddAgentRouter(agentId string, routerId string) error {
+	_, err := c.R().SetBody(map[string]string{"router_id": routerId}).
+		Post(URL_AGENT_L3_ROUTERS.F(agentId))
+	return err
+}
+func (c NeutronV2) RemoveAgentRouter(agentId string, routerId string) error {
+	return DeleteResource(c.ServiceClient, URL_AGENT_L3_ROUTER.F(agentId, routerId))
+}
+
+// 返回的 agent 中包含 HA 路由器在该 agent 上的状态 (ha_state)
+func (c NeutronV2) ListRouterAgents(routerId string) ([]neutron.Agent, error) {
+	return QueryResource[neutron.Agent](c.ServiceClient, URL_ROUTER_L3_AGENTS.F(routerId), nil, "agents")
+}
 
 // security group api
 
@@ -197,6 +322,29 @@ func (c NeutronV2) GetSecurityGroup(id string) (*neutron.SecurityGroup, error) {
 func (c NeutronV2) FindSecurityGroup(idOrName string) (*neutron.SecurityGroup, error) {
 	return QueryByIdOrName(idOrName, c.GetSecurityGroup, c.ListSecurityGroup)
 }
+func (c NeutronV2) CreateSecurityGroup(params map[string]any) (*neutron.SecurityGroup, error) {
+	body := struct {
+		SecurityGroup neutron.SecurityGroup `json:"security_group"`
+	}{}
+	if _, err := c.R().SetBody(map[string]any{"security_group": params}).SetResult(&body).
+		Post(URL_SECURITY_GROUPS.F()); err != nil {
+		return nil, err
+	}
+	return &body.SecurityGroup, nil
+}
+func (c NeutronV2) UpdateSecurityGroup(id string, params map[string]any) (*neutron.SecurityGroup, error) {
+	body := struct {
+		SecurityGroup neutron.SecurityGroup `json:"security_group"`
+	}{}
+	if _, err := c.R().SetBody(map[string]any{"security_group": params}).SetResult(&body).
+		Put(URL_SECURITY_GROUP.F(id)); err != nil {
+		return nil, err
+	}
+	return &body.SecurityGroup, nil
+}
+func (c NeutronV2) DeleteSecurityGroup(id string) error {
+	return DeleteResource(c.ServiceClient, URL_SECURITY_GROUP.F(id))
+}
 
 // security group rule api
 
@@ -207,7 +355,35 @@ func (c NeutronV2) ListSecurityGroupRule(query url.Values) ([]neutron.SecurityGr
 }
 func (c NeutronV2) GetSecurityGroupRule(id string) (*neutron.SecurityGroupRule, error) {
 	return GetResource[neutron.SecurityGroupRule](
-		c.ServiceClient, URL_SECURITY_GROUP_RULES.F(id), "security_group_rule")
+		c.ServiceClient, URL_SECURITY_GROUP_RULE.F(id), "security_group_rule")
+}
+func (c NeutronV2) CreateSecurityGroupRule(params map[string]any) (*neutron.SecurityGroupRule, error) {
+	body := struct {
+		SecurityGroupRule neutron.SecurityGroupRule `json:"security_group_rule"`
+	}{}
+	if _, err := c.R().SetBody(map[string]any{"security_group_rule": params}).SetResult(&body).
+		Post(URL_SECURITY_GROUP_RULES.F()); err != nil {
+		return nil, err
+	}
+	return &body.SecurityGroupRule, nil
+}
+func (c NeutronV2) DeleteSecurityGroupRule(id string) error {
+	return DeleteResource(c.ServiceClient, URL_SECURITY_GROUP_RULE.F(id))
+}
+
+// quota api
+
+func (c NeutronV2) GetQuotaLimits(projectId string) (map[string]int, error) {
+	return GetQuota[int](c.ServiceClient, URL_NEUTRON_QUOTA.F(projectId), nil, "quota")
+}
+func (c NeutronV2) GetQuotaUsages(projectId string) (map[string]neutron.QuotaUsage, error) {
+	return GetQuota[neutron.QuotaUsage](c.ServiceClient, URL_NEUTRON_QUOTA_DETAIL.F(projectId), nil, "quota")
+}
+func (c NeutronV2) GetDefaultQuotaLimits(projectId string) (map[string]int, error) {
+	return GetQuota[int](c.ServiceClient, URL_NEUTRON_QUOTA_DEFAULT.F(projectId), nil, "quota")
+}
+func (c NeutronV2) UpdateQuotaLimits(projectId string, limits map[string]int) (map[string]int, error) {
+	return UpdateQuota(c.ServiceClient, URL_NEUTRON_QUOTA.F(projectId), "quota", limits)
 }
 
 // qos policy api
@@ -216,12 +392,121 @@ func (c NeutronV2) ListQosPolicy(query url.Values) ([]neutron.QosPolicy, error)
 	return QueryResource[neutron.QosPolicy](c.ServiceClient, URL_QOS_POLICIES.F(), query, "policies")
 }
 func (c NeutronV2) GetQosPolicy(id string) (*neutron.QosPolicy, error) {
-	return GetResource[neutron.QosPolicy](c.ServiceClient, URL_QOS_POLICY.F(), "qos_policy")
+	return GetResource[neutron.QosPolicy](c.ServiceClient, URL_QOS_POLICY.F(id), "policy")
 }
 func (c NeutronV2) FindQosPolicy(idOrName string) (*neutron.QosPolicy, error) {
 	return QueryByIdOrName(idOrName, c.GetQosPolicy, c.ListQosPolicy)
 }
+func (c NeutronV2) CreateQosPolicy(params map[string]any) (*neutron.QosPolicy, error) {
+	body := struct {
+		Policy neutron.QosPolicy `json:"policy"`
+	}{}
+	if _, err := c.R().SetBody(map[string]any{"policy": params}).SetResult(&body).
+		Post(URL_QOS_POLICIES.F()); err != nil {
+		return nil, err
+	}
+	return &body.Policy, nil
+}
+func (c NeutronV2) UpdateQosPolicy(id string, params map[string]any) (*neutron.QosPolicy, error) {
+	body := struct {
+		Policy neutron.QosPolicy `json:"policy"`
+	}{}
+	if _, err := c.R().SetBody(map[string]any{"policy": params}).SetResult(&body).
+		Put(URL_QOS_POLICY.F(id)); err != nil {
+		return nil, err
+	}
+	return &body.Policy, nil
+}
+func (c NeutronV2) DeleteQosPolicy(id string) error {
+	return DeleteResource(c.ServiceClient, URL_QOS_POLICY.F(id))
+}
+
+// qos rule api, ruleType: bandwidth_limit, dscp_marking, minimum_bandwidth
+
+func (c NeutronV2) CreateQosRule(policyId string, ruleType string, params map[string]any) (*neutron.QosRule, error) {
+	body := map[string]*neutron.QosRule{}
+	if _, err := c.R().SetBody(map[string]any{ruleType + "_rule": params}).SetResult(&body).
+		Post(URL_QOS_POLICY_RULES.F(policyId, ruleType)); err != nil {
+		return nil, err
+	}
+	rule := body[ruleType+"_rule"]
+	if rule == nil {
+		return nil, fmt.Errorf("%s rule not found in response", ruleType)
+	}
+	rule.QosPolicyId, rule.Type = policyId, ruleType
+	return rule, nil
+}
+func (c NeutronV2) DeleteQosRule(policyId string, ruleType string, id string) error {
+	return DeleteResource(c.ServiceClient, URL_QOS_POLICY_RULE.F(policyId, ruleType, id))
+}
+
+// trunk api
+
+func (c NeutronV2) ListTrunk(query url.Values) ([]neutron.Trunk, error) {
+	return QueryResource[neutron.Trunk](c.ServiceClient, URL_TRUNKS.F(), query, "trunks")
+}
+func (c NeutronV2) GetTrunk(id string) (*neutron.Trunk, error) {
+	return GetResource[neutron.Trunk](c.ServiceClient, URL_TRUNK.F(id), "trunk")
+}
+func (c NeutronV2) FindTrunk(idOrName string) (*neutron.Trunk, error) {
+	return QueryByIdOrName(idOrName, c.GetTrunk, c.ListTrunk)
+}
+func (c NeutronV2) CreateTrunk(params map[string]any) (*neutron.Trunk, error) {
+	body := struct {
+		Trunk neutron.Trunk `json:"trunk"`
+	}{}
+	if _, err := c.R().SetBody(map[string]any{"trunk": params}).SetResult(&body).
+		Post(URL_TRUNKS.F()); err != nil {
+		return nil, err
+	}
+	return &body.Trunk, nil
+}
+func (c NeutronV2) UpdateTrunk(id string, params map[string]any) (*neutron.Trunk, error) {
+	body := struct {
+		Trunk neutron.Trunk `json:"trunk"`
+	}{}
+	if _, err := c.R().SetBody(map[string]any{"trunk": params}).SetResult(&body).
+		Put(URL_TRUNK.F(id)); err != nil {
+		return nil, err
+	}
+	return &body.Trunk, nil
+}
+func (c NeutronV2) DeleteTrunk(id string) error {
+	return DeleteResource(c.ServiceClient, URL_TRUNK.F(id))
+}
+func (c NeutronV2) TrunkAddSubports(id string, subPorts []neutron.SubPort) (*neutron.Trunk, error) {
+	body := neutron.Trunk{}
+	if _, err := c.R().SetBody(map[string]any{"sub_ports": subPorts}).SetResult(&body).
+		Put(URL_TRUNK_ADD_SUBPORTS.F(id)); err != nil {
+		return nil, err
+	}
+	return &body, nil
+}
+
+// 移除子端口时只需要指定 port_id
+func (c NeutronV2) TrunkRemoveSubports(id string, portIds []string) (*neutron.Trunk, error) {
+	subPorts := []map[string]string{}
+	for _, portId := range portIds {
+		subPorts = append(subPorts, map[string]string{"port_id": portId})
+	}
+	body := neutron.Trunk{}
+	if _, err := c.R().SetBody(map[string]any{"sub_ports": subPorts}).SetResult(&body).
+		Put(URL_TRUNK_REMOVE_SUBPORTS.F(id)); err != nil {
+		return nil, err
+	}
+	return &body, nil
+}
 
-func (c NeutronV2) ListQosRule(query url.Values) ([]neutron.QosRule, error) {
-	return QueryResource[neutron.QosRule](c.ServiceClient, URL_QOS_POLICY_RULES.F(), query, "policie_rules")
+func (c NeutronV2) PortWaiter(id string, status string) *Waiter[neutron.Port] {
+	return newStatusWaiter(fmt.Sprintf("port %s", id),
+		func() (*neutron.Port, error) { return c.GetPort(id) },
+		func(p *neutron.Port) string { return p.Status },
+		status)
+}
+func (c NeutronV2) WaitPortStatus(id string, status string, timeout time.Duration) (*neutron.Port, error) {
+	return c.PortWaiter(id, status).WithTimeout(timeout).Wait()
+}
+func (c NeutronV2) WaitPortDeleted(id string, timeout time.Duration) error {
+	_, err := c.PortWaiter(id, "").UntilDeleted().WithTimeout(timeout).Wait()
+	return err
 }
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -20,6 +22,9 @@ import (
 
 const X_OPENSTACK_NOVA_API_VERSION = "X-Openstack-Nova-Api-Version"
 
+// 虚拟机启动完成后控制台日志中的登录提示
+const GUEST_BOOTED_PATTERN = ` login:`
+
 type microVersion struct {
 	Version      int
 	MicroVersion int
@@ -287,7 +292,7 @@ func (c NovaV2) EvacuateServer(id string, password string, host string, force bo
 		data["password"] = password
 	}
 	if host != "" {
-		data["host"] = password
+		data["host"] = host
 	}
 	if force {
 		data["force"] = force
@@ -313,6 +318,94 @@ func (c NovaV2) SetServerState(id string, active bool) error {
 	_, err := c.serverDoAction("os-resetState", id, data)
 	return err
 }
+func (c NovaV2) requireMicroVersion(action string, version string) error {
+	if c.MicroVersionLargeEqual(version) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s requires %s, current is %s",
+		ErrMicroVersionNotSupport, action, version, c.GetMicroVersion())
+}
+func (c NovaV2) LockServer(id string, reason string) error {
+	var params any
+	if reason != "" {
+		if err := c.requireMicroVersion("lock with reason", "2.73"); err != nil {
+			return err
+		}
+		params = map[string]string{"locked_reason": reason}
+	}
+	_, err := c.serverDoAction("lock", id, params)
+	return err
+}
+func (c NovaV2) UnlockServer(id string) error {
+	_, err := c.serverDoAction("unlock", id, nil)
+	return err
+}
+
+// 返回救援模式的管理员密码
+func (c NovaV2) RescueServer(id string, imageRef string, password string) (string, error) {
+	params := map[string]string{}
+	if imageRef != "" {
+		params["rescue_image_ref"] = imageRef
+	}
+	if password != "" {
+		params["adminPass"] = password
+	}
+	result := struct {
+		AdminPass string `json:"adminPass"`
+	}{}
+	_, err := c.serverDoAction("rescue", id, params, &result)
+	return result.AdminPass, err
+}
+func (c NovaV2) UnrescueServer(id string) error {
+	_, err := c.serverDoAction("unrescue", id, nil)
+	return err
+}
+
+// 返回备份的镜像 ID
+func (c NovaV2) CreateServerBackup(id string, name string, backupType string, rotation int) (string, error) {
+	params := map[string]any{"name": name, "backup_type": backupType, "rotation": rotation}
+	result := struct {
+		ImageId string `json:"image_id"`
+	}{}
+	resp, err := c.serverDoAction("createBackup", id, params, &result)
+	if err != nil {
+		return "", err
+	}
+	if result.ImageId != "" {
+		return result.ImageId, nil
+	}
+	// 2.45 之前的版本通过 Location 返回镜像地址
+	location := resp.Header().Get("Location")
+	return location[strings.LastIndex(location, "/")+1:], nil
+}
+func (c NovaV2) TriggerCrashDump(id string) error {
+	if err := c.requireMicroVersion("trigger_crash_dump", "2.17"); err != nil {
+		return err
+	}
+	_, err := c.serverDoAction("trigger_crash_dump", id, nil)
+	return err
+}
+
+// 返回加密后并经过 base64 编码的密码
+func (c NovaV2) GetServerPassword(id string) (string, error) {
+	result := struct {
+		Password string `json:"password"`
+	}{}
+	_, err := c.R().SetResult(&result).Get(URL_SERVER_PASSWORD.F(id))
+	return result.Password, err
+}
+func (c NovaV2) RestoreServer(id string) error {
+	_, err := c.serverDoAction("restore", id, nil)
+	return err
+}
+func (c NovaV2) ForceDeleteServer(id string) error {
+	_, err := c.serverDoAction("forceDelete", id, nil)
+	return err
+}
+func (c NovaV2) ShelveOffloadServer(id string) error {
+	_, err := c.serverDoAction("shelveOffload", id, nil)
+	return err
+}
 
 func (c NovaV2) GetServerConsoleLog(id string, length uint) (*nova.ConsoleLog, error) {
 	params := map[string]any{}
@@ -326,6 +419,126 @@ func (c NovaV2) GetServerConsoleLog(id string, length uint) (*nova.ConsoleLog, e
 	}
 	return &result, nil
 }
+
+// 控制台日志跟踪器, 每次调用 Next 返回新增的日志行
+type ConsoleLogFollower struct {
+	serverId string
+	getLog   func(length uint) (string, error)
+	started  bool
+	tail     []string
+	step     int
+}
+
+// 用于在新的日志中定位上次读取位置的行数
+const CONSOLE_LOG_TAIL_LINES = 3
+
+func (c NovaV2) FollowServerConsoleLog(id string) *ConsoleLogFollower {
+	return &ConsoleLogFollower{
+		serverId: id, step: 100,
+		getLog: func(length uint) (string, error) {
+			consoleLog, err := c.GetServerConsoleLog(id, length)
+			if err != nil {
+				return "", err
+			}
+			return consoleLog.Output, nil
+		},
+	}
+}
+
+// 返回 tail 在 lines 中最后一次出现的位置, 不存在时返回 -1
+func lastIndexOfLines(lines []string, tail []string) int {
+	if len(tail) == 0 {
+		return -1
+	}
+	for i := len(lines) - len(tail); i >= 0; i-- {
+		if slices.Equal(lines[i:i+len(tail)], tail) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 返回新增的完整行以及末尾未换行的内容(例如登录提示符)
+//
+// 每次查询最后 step 行, 通过上次读取的最后几行定位新增的日志; 如果找不到并且返回的
+// 不是完整的日志, 说明新增的日志较多, 增大查询的行数重试; 如果完整的日志中也找不到,
+// 则认为日志被截断或者轮转, 返回全部日志。nova 会限制日志的大小, 日志达到上限后返回
+// 的内容会整体滑动, 因此不能根据行数判断新增的日志
+//
+// 注意: nova 截取日志时按 "\n" 分割计数, 日志以换行结尾时末尾的空字符串也算一行
+func (f *ConsoleLogFollower) Next() ([]string, string, error) {
+	for length := f.step; ; length *= 2 {
+		output, err := f.getLog(uint(length))
+		if err != nil {
+			return nil, "", err
+		}
+		lines := strings.Split(output, "\n")
+		complete := len(lines) < length
+		// 最后一个元素为末尾未换行的内容, 以换行结尾时为空字符串
+		lines, pending := lines[:len(lines)-1], lines[len(lines)-1]
+
+		index := -1
+		if f.started {
+			index = lastIndexOfLines(lines, f.tail)
+		}
+		if index < 0 && !complete {
+			continue
+		}
+		newLines := lines
+		if index >= 0 {
+			newLines = lines[index+len(f.tail):]
+		} else if f.started && len(f.tail) > 0 {
+			console.Debug("[%s] console log is truncated or rotated", f.serverId)
+		}
+		f.started = true
+		if len(newLines) > 0 {
+			f.tail = slices.Clone(lines[max(0, len(lines)-CONSOLE_LOG_TAIL_LINES):])
+		}
+		return newLines, pending, nil
+	}
+}
+
+// 等待控制台日志匹配指定的正则表达式, onLines 不为空时, 新增的日志行会传给 onLines
+func (c NovaV2) WaitConsoleLogMatch(id string, pattern *regexp.Regexp, timeout time.Duration,
+	onLines func(lines []string, pending string)) error {
+	follower := c.FollowServerConsoleLog(id)
+	matched := false
+	err := utility.RetryError(
+		utility.RetryCondition{
+			Timeout:      timeout,
+			IntervalMin:  time.Second * 2,
+			IntervalStep: time.Second,
+			IntervalMax:  time.Second * 5,
+		},
+		func() (bool, error) {
+			lines, pending, err := follower.Next()
+			if err != nil {
+				console.Debug("[%s] get console log failed: %s", id, err)
+				return true, err
+			}
+			if onLines != nil {
+				onLines(lines, pending)
+			}
+			for _, line := range append(lines, pending) {
+				if pattern.MatchString(line) {
+					matched = true
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+	)
+	if matched {
+		return nil
+	}
+	return fmt.Errorf("wait for '%s' in console log failed: %w", pattern, err)
+}
+
+// 通过控制台日志中的登录提示判断虚拟机是否已经启动完成
+func (c NovaV2) WaitServerGuestBooted(id string, timeout time.Duration) error {
+	return c.WaitConsoleLogMatch(id, regexp.MustCompile(GUEST_BOOTED_PATTERN), timeout, nil)
+}
+
 func (c NovaV2) getVNCConsole(id string, consoleType string) (*nova.Console, error) {
 	params := map[string]any{"type": consoleType}
 	result := map[string]*nova.Console{"console": {}}
@@ -352,10 +565,35 @@ func (c NovaV2) getRemoteConsole(id string, protocol string, consoleType string)
 	}
 	return &result.RemoteConsole, nil
 }
+func (c NovaV2) getSerialConsole(id string, consoleType string) (*nova.Console, error) {
+	params := map[string]any{"type": consoleType}
+	result := map[string]*nova.Console{"console": {}}
+	_, err := c.serverDoAction("os-getSerialConsole", id, params, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result["console"], nil
+}
+
+// 控制台类型对应的协议
+var consoleProtocols = map[string]string{
+	"novnc": "vnc", "xvpvnc": "vnc",
+	"spice-html5": "spice",
+	"rdp-html5":   "rdp",
+	"serial":      "serial",
+	"webmks":      "mks",
+}
+
 func (c NovaV2) GetServerConsoleUrl(id string, consoleType string) (*nova.Console, error) {
 	if c.MicroVersionLargeEqual("2.6") {
-		// TODO: do not set "vnc" directly
-		return c.getRemoteConsole(id, "vnc", consoleType)
+		protocol, ok := consoleProtocols[consoleType]
+		if !ok {
+			protocol = "vnc"
+		}
+		return c.getRemoteConsole(id, protocol, consoleType)
+	}
+	if consoleType == "serial" {
+		return c.getSerialConsole(id, consoleType)
 	}
 	return c.getVNCConsole(id, consoleType)
 }
@@ -564,6 +802,23 @@ func (c NovaV2) DeleteFlavorExtraSpec(id string, extraSpec string) error {
 	}
 	return err
 }
+func (c NovaV2) ListFlavorAccess(id string) ([]nova.FlavorAccess, error) {
+	return QueryResource[nova.FlavorAccess](c.ServiceClient, URL_FLAVOR_ACCESS.F(id), nil, "flavor_access")
+}
+func (c NovaV2) flavorDoAction(action, id string, params any) ([]nova.FlavorAccess, error) {
+	result := struct {
+		FlavorAccess []nova.FlavorAccess `json:"flavor_access"`
+	}{}
+	_, err := c.R().SetBody(map[string]any{action: params}).
+		SetResult(&result).Post(URL_FLAVOR_ACTION.F(id))
+	return result.FlavorAccess, err
+}
+func (c NovaV2) AddFlavorAccess(id string, projectId string) ([]nova.FlavorAccess, error) {
+	return c.flavorDoAction("addTenantAccess", id, map[string]string{"tenant": projectId})
+}
+func (c NovaV2) RemoveFlavorAccess(id string, projectId string) ([]nova.FlavorAccess, error) {
+	return c.flavorDoAction("removeTenantAccess", id, map[string]string{"tenant": projectId})
+}
 
 // hypervisor api
 
@@ -637,16 +892,17 @@ func (c NovaV2) GetHypervisorFlavorCapacities(query url.Values) (*nova.FlavorCap
 
 func (c NovaV2) ListKeypair(query url.Values) ([]nova.Keypair, error) {
 	return QueryResource[nova.Keypair](
-		c.ServiceClient, URL_KEYPAIRS.F(), query, "keyapirs")
+		c.ServiceClient, URL_KEYPAIRS.F(), query, "keypairs")
 }
 func (c NovaV2) GetKeypair(name string) (*nova.Keypair, error) {
-	return GetResource[nova.Keypair](
-		c.ServiceClient, URL_KEYPAIR.F(), "keyapirs",
-	)
+	return GetResource[nova.Keypair](c.ServiceClient, URL_KEYPAIR.F(name), "")
 }
 func (c NovaV2) CreateKeypair(name string, keyType string, opt nova.KeypairOpt) (*nova.Keypair, error) {
 	result := nova.Keypair{}
-
+	if opt.PublicKey == "" && c.MicroVersionLargeEqual("2.92") {
+		return nil, fmt.Errorf("%w: server side key generation is removed since 2.92, public key is required",
+			ErrMicroVersionNotSupport)
+	}
 	keypairOption := map[string]string{"name": name, "type": keyType}
 	if opt.PublicKey != "" {
 		keypairOption["public_key"] = opt.PublicKey
@@ -788,6 +1044,31 @@ func (c NovaV2) ListMigration(query url.Values) ([]nova.Migration, error) {
 		c.ServiceClient, URL_MIGRATIONS_LIST.F(), query, "migrations")
 }
 
+// 分页查询所有迁移记录, 微版本小于 2.59 时不支持分页
+func (c NovaV2) ListAllMigrations(query url.Values, pageSize int) ([]nova.Migration, error) {
+	if !c.MicroVersionLargeEqual("2.59") || pageSize <= 0 {
+		return c.ListMigration(query)
+	}
+	pageQuery := url.Values{}
+	for k, v := range query {
+		pageQuery[k] = v
+	}
+	pageQuery.Set("limit", strconv.Itoa(pageSize))
+	migrations := []nova.Migration{}
+	for {
+		page, err := c.ListMigration(pageQuery)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, page...)
+		if len(page) < pageSize || page[len(page)-1].UUID == "" {
+			break
+		}
+		pageQuery.Set("marker", page[len(page)-1].UUID)
+	}
+	return migrations, nil
+}
+
 // avaliable zone api
 
 func (c NovaV2) ListAZ(query url.Values, detail ...bool) ([]nova.AvailabilityZone, error) {
@@ -849,32 +1130,59 @@ func (c NovaV2) FindAgg(idOrName string) (*nova.Aggregate, error) {
 	}
 }
 
-func (c NovaV2) AggAddHost(id int, host string) (*nova.Aggregate, error) {
-	body := struct {
-		AddHost map[string]string `json:"add_host"`
-	}{
-		AddHost: map[string]string{"host": host},
-	}
+func (c NovaV2) aggDoAction(id int, action string, params any) (*nova.Aggregate, error) {
 	result := struct{ Aggregate nova.Aggregate }{}
-	if _, err := c.R().SetResult(&result).SetBody(body).
+	if _, err := c.R().SetResult(&result).SetBody(map[string]any{action: params}).
 		Post(URL_AGGREGATE_ACTION.F(id)); err != nil {
 		return nil, err
 	}
 	return &result.Aggregate, nil
 }
+func (c NovaV2) AggAddHost(id int, host string) (*nova.Aggregate, error) {
+	return c.aggDoAction(id, "add_host", map[string]string{"host": host})
+}
 func (c NovaV2) AggRemoveHost(id int, host string) (*nova.Aggregate, error) {
-	body := struct {
-		RemoveHost map[string]string `json:"remove_host"`
-	}{
-		RemoveHost: map[string]string{"host": host},
+	return c.aggDoAction(id, "remove_host", map[string]string{"host": host})
+}
+
+// 更新聚合的名称和可用域, 为空时不更新
+func (c NovaV2) UpdateAgg(id int, name string, az string) (*nova.Aggregate, error) {
+	params := map[string]string{}
+	if name != "" {
+		params["name"] = name
+	}
+	if az != "" {
+		params["availability_zone"] = az
 	}
 	result := struct{ Aggregate nova.Aggregate }{}
-	if _, err := c.R().SetResult(&result).SetBody(body).
-		Post(URL_AGGREGATE_ACTION.F(id)); err != nil {
+	if _, err := c.R().SetResult(&result).SetBody(map[string]any{"aggregate": params}).
+		Put(URL_AGGREGATE.F(id)); err != nil {
 		return nil, err
 	}
 	return &result.Aggregate, nil
 }
+func (c NovaV2) AggSetMetadata(id int, metadata map[string]string) (*nova.Aggregate, error) {
+	return c.aggDoAction(id, "set_metadata", map[string]any{"metadata": metadata})
+}
+
+// 删除聚合元数据, nova 通过将值设置为 null 实现删除
+func (c NovaV2) AggUnsetMetadata(id int, keys []string) (*nova.Aggregate, error) {
+	metadata := map[string]any{}
+	for _, key := range keys {
+		metadata[key] = nil
+	}
+	return c.aggDoAction(id, "set_metadata", map[string]any{"metadata": metadata})
+}
+func (c NovaV2) AggCacheImages(id int, imageIds []string) error {
+	if err := c.requireMicroVersion("cache images", "2.81"); err != nil {
+		return err
+	}
+	images := lo.Map(imageIds, func(imageId string, _ int) map[string]string {
+		return map[string]string{"id": imageId}
+	})
+	_, err := c.R().SetBody(map[string]any{"cache": images}).Post(URL_AGGREGATE_IMAGES.F(id))
+	return err
+}
 
 // server group api
 
@@ -885,95 +1193,126 @@ func (c NovaV2) ListServerGroup(query url.Values) ([]nova.ServerGroup, error) {
 
 // quota api
 
-func (c NovaV2) GetQuotaSet(projectId string) (*nova.QuotaSet, error) {
-	return GetResource[nova.QuotaSet](
-		c.ServiceClient, URL_QUOTA_DETAIL.F(projectId), "quota_set",
-	)
+func (c NovaV2) GetQuotaLimits(projectId string) (map[string]int, error) {
+	return GetQuota[int](c.ServiceClient, URL_QUOTA_DETAIL.F(projectId), nil, "quota_set")
 }
-
-// 扩展的方法
-
-func (c NovaV2) WaitServerStatus(serverId string, status string, interval int) (*nova.Server, error) {
-	var server *nova.Server
-	err := utility.RetryWithError(
-		utility.RetryCondition{
-			Timeout:     time.Minute * 10,
-			IntervalMin: time.Second * time.Duration(interval),
-		},
-		ErrServerStatusNotExpect,
-		func() error {
-			server, err := c.GetServer(serverId)
-			if err != nil {
-				return nil
-			}
-			console.Info("[server: %s] status: %s, taskState: %s", server.Id, server.Status, server.TaskState)
-			if strings.EqualFold(server.Status, "ERROR") {
-				return fmt.Errorf("%w, message: %s", ErrServerIsError, server.Fault.Message)
-			}
-			if strings.EqualFold(server.Status, status) {
-				return nil
-			}
-			return fmt.Errorf("%w, expect: %s, but got: %s",
-				ErrServerStatusNotExpect, status, server.Status)
-		},
-	)
-	return server, err
+func (c NovaV2) GetQuotaUsages(projectId string) (map[string]nova.QuotaUsage, error) {
+	return GetQuota[nova.QuotaUsage](c.ServiceClient, URL_QUOTA_USAGE.F(projectId), nil, "quota_set")
+}
+func (c NovaV2) GetDefaultQuotaLimits(projectId string) (map[string]int, error) {
+	return GetQuota[int](c.ServiceClient, URL_QUOTA_DEFAULTS.F(projectId), nil, "quota_set")
+}
+func (c NovaV2) UpdateQuotaLimits(projectId string, limits map[string]int) (map[string]int, error) {
+	return UpdateQuota(c.ServiceClient, URL_QUOTA_DETAIL.F(projectId), "quota_set", limits)
+}
+func (c NovaV2) GetQuotaClassLimits(class string) (map[string]int, error) {
+	return GetQuota[int](c.ServiceClient, URL_QUOTA_CLASS.F(class), nil, "quota_class_set")
+}
+func (c NovaV2) UpdateQuotaClassLimits(class string, limits map[string]int) (map[string]int, error) {
+	return UpdateQuota(c.ServiceClient, URL_QUOTA_CLASS.F(class), "quota_class_set", limits)
 }
 
-func (c NovaV2) WaitServerBooted(id string) (*nova.Server, error) {
+// usage api
+
+func (c NovaV2) ListTenantUsage(query url.Values) ([]nova.TenantUsage, error) {
+	query = cloneQuery(query)
+	usages := []nova.TenantUsage{}
 	for {
-		server, err := c.GetServer(id)
+		result := struct {
+			TenantUsages      []nova.TenantUsage `json:"tenant_usages"`
+			TenantUsagesLinks []model.Link       `json:"tenant_usages_links"`
+		}{}
+		_, err := c.R().SetQueryParamsFromValues(query).SetResult(&result).Get(URL_TENANT_USAGES.F())
 		if err != nil {
-			return server, err
+			return nil, err
 		}
-		console.Info("[%s] %s", id, server.AllStatus())
-		if server.IsError() {
-			return server, fmt.Errorf("server %s is error", server.Id)
+		for _, item := range result.TenantUsages {
+			if _, index, ok := lo.FindIndexOf(usages, func(u nova.TenantUsage) bool {
+				return u.TenantId == item.TenantId
+			}); ok {
+				usages[index].Merge(item)
+			} else {
+				usages = append(usages, item)
+			}
 		}
-		if server.IsActive() && server.Host != "" {
-			return server, nil
+		marker := model.NextMarker(result.TenantUsagesLinks)
+		if marker == "" {
+			return usages, nil
 		}
-		time.Sleep(time.Second * 2)
+		query.Set("marker", marker)
 	}
 }
-func (c NovaV2) WaitServerDeleted(id string) error {
-	return utility.RetryWithError(
-		utility.RetryCondition{
-			Timeout:     time.Second * 60 * 10,
-			IntervalMin: time.Second * time.Duration(2)},
-		ErrServerIsNotDeleted,
-		func() error {
-			server, err := c.GetServer(id)
-			if err == nil {
-				console.Info("[%s] %s", id, server.AllStatus())
-				return ErrServerIsNotDeleted
-			}
-			if errors.Is(err, session.ErrHTTP404) {
-				console.Info("[%s] deleted", id)
-				return nil
-			} else {
-				return err
-			}
-		},
-	)
-}
-func (c NovaV2) WaitServerTask(id string, taskState string) (*nova.Server, error) {
+func (c NovaV2) GetTenantUsage(projectId string, query url.Values) (*nova.TenantUsage, error) {
+	query = cloneQuery(query)
+	usage := nova.TenantUsage{TenantId: projectId}
 	for {
-		server, err := c.GetServer(id)
+		result := struct {
+			TenantUsage      nova.TenantUsage `json:"tenant_usage"`
+			TenantUsageLinks []model.Link     `json:"tenant_usage_links"`
+		}{}
+		_, err := c.R().SetQueryParamsFromValues(query).SetResult(&result).Get(URL_TENANT_USAGE.F(projectId))
 		if err != nil {
 			return nil, err
 		}
-		console.Info("[%s] %s progress: %d", id, server.AllStatus(), int(server.Progress))
-
-		if strings.EqualFold(server.Status, "ERROR") {
-			return nil, fmt.Errorf("server %s status is ERROR", id)
-		}
-		if strings.EqualFold(server.TaskState, taskState) {
-			return server, nil
+		usage.Start, usage.Stop = result.TenantUsage.Start, result.TenantUsage.Stop
+		usage.Merge(result.TenantUsage)
+		marker := model.NextMarker(result.TenantUsageLinks)
+		if marker == "" {
+			return &usage, nil
 		}
-		time.Sleep(time.Second * 2)
+		query.Set("marker", marker)
 	}
 }
+
+// 扩展的方法
+
+// 虚拟机等待器, 虚拟机状态为 ERROR 时结束等待
+func (c NovaV2) ServerWaiter(id string) *Waiter[nova.Server] {
+	return NewWaiter(fmt.Sprintf("server %s", id), func() (*nova.Server, error) {
+		return c.GetServer(id)
+	}).FailWhen(func(server *nova.Server) error {
+		if server.IsError() {
+			return fmt.Errorf("%w, message: %s", ErrServerIsError, server.Fault.Message)
+		}
+		return nil
+	}).WithProgress(func(server *nova.Server) {
+		console.Info("[%s] %s", id, server.AllStatus())
+	})
+}
+
+func (c NovaV2) WaitServerStatus(serverId string, status string, interval int) (*nova.Server, error) {
+	return c.ServerWaiter(serverId).
+		Until(func(server *nova.Server) bool {
+			return strings.EqualFold(server.Status, status)
+		}).
+		WithInterval(utility.DefaultInterval{Interval: time.Second * time.Duration(interval)}).
+		WithTimeout(DEFAULT_WAIT_TIMEOUT).
+		Wait()
+}
+
+func (c NovaV2) WaitServerBooted(id string) (*nova.Server, error) {
+	return c.ServerWaiter(id).
+		Until(func(server *nova.Server) bool {
+			return server.IsActive() && server.Host != ""
+		}).
+		Wait()
+}
+func (c NovaV2) WaitServerDeleted(id string) error {
+	_, err := c.ServerWaiter(id).FailWhen(nil).UntilDeleted().
+		WithTimeout(DEFAULT_WAIT_TIMEOUT).
+		Wait()
+	return err
+}
+func (c NovaV2) WaitServerTask(id string, taskState string) (*nova.Server, error) {
+	return c.ServerWaiter(id).
+		Until(func(server *nova.Server) bool {
+			return strings.EqualFold(server.TaskState, taskState)
+		}).
+		WithProgress(func(server *nova.Server) {
+			console.Info("[%s] %s progress: %d", id, server.AllStatus(), int(server.Progress))
+		}).
+		Wait()
+}
 func (c NovaV2) WaitServerResized(id string, newFlavorName string) (*nova.Server, error) {
 	server, err := c.WaitServerTask(id, "")
 	if err != nil {
@@ -989,22 +1328,11 @@ func (c NovaV2) StopServerAndWait(id string) error {
 	if err := c.StopServer(id); err != nil {
 		return err
 	}
-	return utility.RetryWithError(
-		utility.RetryCondition{
-			Timeout:     time.Minute * 30,
-			IntervalMin: time.Second * 2},
-		ErrServerNotStopped,
-		func() error {
-			server, err := c.GetServer(id)
-			if err != nil {
-				return fmt.Errorf("get server %s failed: %s", id, err)
-			}
-			if server.IsStopped() {
-				return nil
-			}
-			return fmt.Errorf("%w: %s", ErrServerNotStopped, id)
-		},
-	)
+	_, err := c.ServerWaiter(id).
+		Until(func(server *nova.Server) bool { return server.IsStopped() }).
+		WithTimeout(time.Minute * 30).
+		Wait()
+	return err
 }
 func (c NovaV2) WaitServerRebooted(id string, newFlavorName string) (*nova.Server, error) {
 	server, err := c.WaitServerTask(id, "")
@@ -1085,59 +1413,17 @@ func (c NovaV2) DeleteServerVolumeAndWait(id string, volumeId string, waitSecond
 	}
 }
 
-func (c NovaV2) CopyFlavor(id string, newName string, newId string,
-	newVcpus int, newRam int, newDisk int, newSwap int,
-	newEphemeral int, newRxtxFactor float32, setProperties map[string]string,
-	unsetProperties []string,
-) (*nova.Flavor, error) {
-	console.Info("Show flavor")
-	flavor, err := c.GetFlavor(id)
-	if err != nil {
-		return nil, err
-	}
-	flavor.Name = newName
-	flavor.Id = newId
-	if newVcpus != 0 {
-		flavor.Vcpus = newVcpus
-	}
-	if newRam != 0 {
-		flavor.Ram = int(newRam)
-	}
-	if newDisk != 0 {
-		flavor.Disk = newDisk
-	}
-	if newSwap != 0 {
-		flavor.Swap = newSwap
-	}
-	if newEphemeral != 0 {
-		flavor.Ephemeral = newEphemeral
-	}
-	if newRxtxFactor != 0 {
-		flavor.RXTXFactor = newRxtxFactor
-	}
-	console.Info("Show flavor extra specs")
-	extraSpecs, err := c.GetFlavorExtraSpecs(id)
+// 将私有规格的项目访问权限复制到新的规格
+func (c NovaV2) CopyFlavorAccess(srcId string, destId string) error {
+	accessList, err := c.ListFlavorAccess(srcId)
 	if err != nil {
-		return nil, err
-	}
-	for k, v := range setProperties {
-		extraSpecs[k] = v
+		return fmt.Errorf("list flavor access failed, %v", err)
 	}
-	for _, k := range unsetProperties {
-		delete(extraSpecs, k)
-	}
-	console.Info("Create new flavor")
-	newFlavor, err := c.CreateFlavor(*flavor)
-	if err != nil {
-		return nil, fmt.Errorf("create flavor failed, %v", err)
-	}
-	if len(extraSpecs) != 0 {
-		console.Info("Set new flavor extra specs")
-		_, err = c.SetFlavorExtraSpecs(newFlavor.Id, extraSpecs)
-		if err != nil {
-			return nil, fmt.Errorf("set flavor extra specs failed, %v", err)
+	for _, access := range accessList {
+		console.Info("add flavor access for project %s", access.TenantId)
+		if _, err := c.AddFlavorAccess(destId, access.TenantId); err != nil {
+			return fmt.Errorf("add flavor access for project %s failed, %v", access.TenantId, err)
 		}
-		newFlavor.ExtraSpecs = extraSpecs
 	}
-	return newFlavor, nil
+	return nil
 }
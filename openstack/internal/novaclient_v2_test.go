@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// 模拟 nova 的 _tail_log: 按 "\n" 分割后取最后 length 行, limit 不为 0 时模拟日志大小上限
+type fakeConsoleLog struct {
+	output    string
+	limit     int
+	maxLength uint
+}
+
+func (l *fakeConsoleLog) tail(length uint) (string, error) {
+	l.maxLength = max(l.maxLength, length)
+	lines := strings.Split(l.output, "\n")
+	if l.limit > 0 && len(lines) > l.limit {
+		lines = lines[len(lines)-l.limit:]
+	}
+	if len(lines) > int(length) {
+		lines = lines[len(lines)-int(length):]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+func (l *fakeConsoleLog) write(start, end int) {
+	for i := start; i < end; i++ {
+		l.output += fmt.Sprintf("line %d\n", i)
+	}
+}
+
+func expectLines(start, end int) []string {
+	lines := []string{}
+	for i := start; i < end; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	return lines
+}
+
+func TestConsoleLogFollowerLongerThanStep(t *testing.T) {
+	log := &fakeConsoleLog{}
+	follower := &ConsoleLogFollower{serverId: "test", step: 10, getLog: log.tail}
+
+	log.write(0, 35)
+	lines, pending, err := follower.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(lines, expectLines(0, 35)) || pending != "" {
+		t.Fatalf("expect 35 lines, got %d lines, pending %q", len(lines), pending)
+	}
+
+	// 日志没有变化时不应重复输出
+	for i := 0; i < 3; i++ {
+		if lines, _, _ := follower.Next(); len(lines) != 0 {
+			t.Fatalf("expect no new lines, got %v", lines)
+		}
+	}
+
+	log.write(35, 60)
+	log.output += "login: "
+	lines, pending, _ = follower.Next()
+	if !slices.Equal(lines, expectLines(35, 60)) {
+		t.Fatalf("expect lines 35-59, got %v", lines)
+	}
+	if pending != "login: " {
+		t.Fatalf("expect pending 'login: ', got %q", pending)
+	}
+}
+
+func TestConsoleLogFollowerRotated(t *testing.T) {
+	log := &fakeConsoleLog{}
+	follower := &ConsoleLogFollower{serverId: "test", step: 10, getLog: log.tail}
+
+	log.write(0, 20)
+	follower.Next()
+
+	log.output = ""
+	log.write(100, 105)
+	lines, _, _ := follower.Next()
+	if !slices.Equal(lines, expectLines(100, 105)) {
+		t.Fatalf("expect lines 100-104 after rotation, got %v", lines)
+	}
+}
+
+func TestConsoleLogFollowerCappedWindow(t *testing.T) {
+	log := &fakeConsoleLog{limit: 21}
+	follower := &ConsoleLogFollower{serverId: "test", step: 10, getLog: log.tail}
+
+	log.write(0, 50)
+	lines, _, _ := follower.Next()
+	if !slices.Equal(lines, expectLines(30, 50)) {
+		t.Fatalf("expect lines 30-49, got %v", lines)
+	}
+	// 日志达到上限后窗口整体滑动, 只返回新增的行
+	for i := 50; i < 80; i += 5 {
+		log.write(i, i+5)
+		log.maxLength = 0
+		lines, _, _ = follower.Next()
+		if !slices.Equal(lines, expectLines(i, i+5)) {
+			t.Fatalf("expect lines %d-%d, got %v", i, i+4, lines)
+		}
+		if log.maxLength > 10 {
+			t.Fatalf("expect fetch at most 10 lines, fetched %d", log.maxLength)
+		}
+		if lines, _, _ = follower.Next(); len(lines) != 0 {
+			t.Fatalf("expect no new lines, got %v", lines)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/BytemanD/skyman/openstack/model/placement"
+)
+
+const (
+	OPENSTACK_API_VERSION = "OpenStack-API-Version"
+	// 1.17 支持在 allocation candidates 中查询 required traits
+	PLACEMENT_DEFAULT_MICRO_VERSION = "1.17"
+	// 1.22 支持在 required 中使用 !TRAIT 排除 trait
+	PLACEMENT_FORBIDDEN_TRAITS_MICRO_VERSION = "1.22"
+)
+
+type PlacementV1 struct{ *ServiceClient }
+
+func (c PlacementV1) SetMicroVersion(version string) {
+	c.SetHeader(OPENSTACK_API_VERSION, "placement "+version)
+}
+
+// resource provider api
+
+func (c PlacementV1) ListResourceProviders(query url.Values) ([]placement.ResourceProvider, error) {
+	return QueryResource[placement.ResourceProvider](c.ServiceClient, URL_RESOURCE_PROVIDERS.F(), query, "resource_providers")
+}
+func (c PlacementV1) GetResourceProvider(uuid string) (*placement.ResourceProvider, error) {
+	return GetResource[placement.ResourceProvider](c.ServiceClient, URL_RESOURCE_PROVIDER.F(uuid), "")
+}
+func (c PlacementV1) FindResourceProvider(idOrName string) (*placement.ResourceProvider, error) {
+	providers, err := c.ListResourceProviders(url.Values{"uuid": []string{idOrName}})
+	if err == nil && len(providers) == 1 {
+		return &providers[0], nil
+	}
+	providers, err = c.ListResourceProviders(url.Values{"name": []string{idOrName}})
+	if err != nil {
+		return nil, err
+	}
+	switch len(providers) {
+	case 0:
+		return nil, ErrResourceNotFound
+	case 1:
+		return &providers[0], nil
+	default:
+		return nil, ErrResourceMulti
+	}
+}
+func (c PlacementV1) GetInventories(uuid string) (map[string]placement.Inventory, error) {
+	result := struct {
+		Inventories map[string]placement.Inventory `json:"inventories"`
+	}{}
+	_, err := c.R().SetResult(&result).Get(URL_RESOURCE_PROVIDER_INVENTORIES.F(uuid))
+	return result.Inventories, err
+}
+func (c PlacementV1) GetUsages(uuid string) (map[string]int, error) {
+	result := struct {
+		Usages map[string]int `json:"usages"`
+	}{}
+	_, err := c.R().SetResult(&result).Get(URL_RESOURCE_PROVIDER_USAGES.F(uuid))
+	return result.Usages, err
+}
+
+// 返回资源提供者的库存以及使用量
+func (c PlacementV1) GetProviderResources(uuid string) ([]placement.ProviderResource, error) {
+	inventories, err := c.GetInventories(uuid)
+	if err != nil {
+		return nil, err
+	}
+	usages, err := c.GetUsages(uuid)
+	if err != nil {
+		return nil, err
+	}
+	resources := []placement.ProviderResource{}
+	for rc, inventory := range inventories {
+		resources = append(resources, placement.ProviderResource{
+			ResourceClass: rc, Inventory: inventory, Used: usages[rc],
+		})
+	}
+	return resources, nil
+}
+
+// 返回资源提供者上各个 consumer 的分配
+func (c PlacementV1) GetProviderAllocations(uuid string) (map[string]placement.Allocation, error) {
+	result := struct {
+		Allocations map[string]placement.Allocation `json:"allocations"`
+	}{}
+	_, err := c.R().SetResult(&result).Get(URL_RESOURCE_PROVIDER_ALLOCATIONS.F(uuid))
+	return result.Allocations, err
+}
+func (c PlacementV1) GetTraits(uuid string) ([]string, error) {
+	result := struct {
+		Traits []string `json:"traits"`
+	}{}
+	_, err := c.R().SetResult(&result).Get(URL_RESOURCE_PROVIDER_TRAITS.F(uuid))
+	return result.Traits, err
+}
+func (c PlacementV1) GetAggregates(uuid string) ([]string, error) {
+	result := struct {
+		Aggregates []string `json:"aggregates"`
+	}{}
+	_, err := c.R().SetResult(&result).Get(URL_RESOURCE_PROVIDER_AGGREGATES.F(uuid))
+	return result.Aggregates, err
+}
+
+// allocation api
+
+func (c PlacementV1) GetAllocations(consumer string) (*placement.ConsumerAllocations, error) {
+	return GetResource[placement.ConsumerAllocations](c.ServiceClient, URL_ALLOCATION.F(consumer), "")
+}
+func (c PlacementV1) ListAllocationCandidates(query url.Values) (*placement.AllocationCandidates, error) {
+	result := placement.AllocationCandidates{}
+	req := c.R().SetQueryParamsFromValues(query).SetResult(&result)
+	if strings.Contains(query.Get("required"), "!") {
+		req.SetHeader(OPENSTACK_API_VERSION, "placement "+PLACEMENT_FORBIDDEN_TRAITS_MICRO_VERSION)
+	}
+	_, err := req.Get(URL_ALLOCATION_CANDIDATES.F())
+	return &result, err
+}
@@ -113,6 +113,14 @@ func NewServiceClient(regionName string, sType, sName, sInterface string, versio
 	return client
 }
 
+func cloneQuery(query url.Values) url.Values {
+	cloned := url.Values{}
+	for k, v := range query {
+		cloned[k] = append([]string{}, v...)
+	}
+	return cloned
+}
+
 func QueryResource[T any](c *ServiceClient, u string, query url.Values, bodyKey string) ([]T, error) {
 	result := map[string]any{}
 	_, err := c.R().SetQueryParamsFromValues(query).SetResult(&result).Get(u)
@@ -156,6 +164,35 @@ func FindResource[T any](c *ServiceClient, u string, bodyKey string) (*T, error)
 	return result[bodyKey], err
 }
 
+// 配额的返回体中包含 id 等非配额字段, 解析时忽略这些字段
+func parseQuota[T any](body map[string]json.RawMessage) map[string]T {
+	quotas := map[string]T{}
+	for k, v := range body {
+		var item T
+		if err := json.Unmarshal(v, &item); err != nil {
+			continue
+		}
+		quotas[k] = item
+	}
+	return quotas
+}
+func GetQuota[T any](c *ServiceClient, u string, query url.Values, bodyKey string) (map[string]T, error) {
+	result := map[string]map[string]json.RawMessage{}
+	_, err := c.R().SetQueryParamsFromValues(query).SetResult(&result).Get(u)
+	if err != nil {
+		return nil, err
+	}
+	return parseQuota[T](result[bodyKey]), nil
+}
+func UpdateQuota(c *ServiceClient, u string, bodyKey string, limits map[string]int) (map[string]int, error) {
+	result := map[string]map[string]json.RawMessage{}
+	_, err := c.R().SetBody(map[string]any{bodyKey: limits}).SetResult(&result).Put(u)
+	if err != nil {
+		return nil, err
+	}
+	return parseQuota[int](result[bodyKey]), nil
+}
+
 func QueryByIdOrName[T any](
 	idOrName string,
 	showFunc func(id string) (*T, error),
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/openstack/session"
+	"github.com/BytemanD/skyman/utility"
+)
+
+const DEFAULT_WAIT_TIMEOUT = time.Minute * 10
+
+// 通用的资源等待器
+//
+// 每次轮询通过 Get 获取资源, 依次判断:
+//   - Failed 返回错误时立即结束
+//   - Ready 返回 true 时结束
+//   - 超过 Timeout 时返回 ErrWaitTimeout, Timeout 为 0 表示不超时
+type Waiter[T any] struct {
+	Resource   string
+	Get        func() (*T, error)
+	Ready      func(*T) bool
+	Failed     func(*T) error
+	Interval   utility.Interval
+	Timeout    time.Duration
+	OnProgress func(*T)
+	deleted    bool
+}
+
+func NewWaiter[T any](resource string, get func() (*T, error)) *Waiter[T] {
+	return &Waiter[T]{
+		Resource: resource,
+		Get:      get,
+		Interval: &utility.StepInterval{Max: time.Second * 10, Step: time.Second * 2},
+	}
+}
+func (w *Waiter[T]) Until(ready func(*T) bool) *Waiter[T] {
+	w.Ready = ready
+	return w
+}
+
+// 等待资源被删除, 获取资源返回 404 时结束
+func (w *Waiter[T]) UntilDeleted() *Waiter[T] {
+	w.deleted = true
+	w.Ready = func(*T) bool { return false }
+	return w
+}
+func (w *Waiter[T]) FailWhen(failed func(*T) error) *Waiter[T] {
+	w.Failed = failed
+	return w
+}
+func (w *Waiter[T]) WithTimeout(timeout time.Duration) *Waiter[T] {
+	w.Timeout = timeout
+	return w
+}
+func (w *Waiter[T]) WithInterval(interval utility.Interval) *Waiter[T] {
+	w.Interval = interval
+	return w
+}
+func (w *Waiter[T]) WithProgress(onProgress func(*T)) *Waiter[T] {
+	w.OnProgress = onProgress
+	return w
+}
+
+func (w *Waiter[T]) Wait() (*T, error) {
+	if w.Ready == nil {
+		return nil, fmt.Errorf("waiter of %s has no ready condition", w.Resource)
+	}
+	startTime := time.Now()
+	for {
+		item, err := w.Get()
+		if err != nil {
+			if w.deleted && errors.Is(err, session.ErrHTTP404) {
+				console.Info("[%s] deleted", w.Resource)
+				return nil, nil
+			}
+			return nil, fmt.Errorf("get %s failed: %w", w.Resource, err)
+		}
+		if w.OnProgress != nil {
+			w.OnProgress(item)
+		}
+		if w.Failed != nil {
+			if err := w.Failed(item); err != nil {
+				return item, fmt.Errorf("%s: %w", w.Resource, err)
+			}
+		}
+		if w.Ready(item) {
+			return item, nil
+		}
+		if w.Timeout > 0 && time.Since(startTime) >= w.Timeout {
+			return item, fmt.Errorf("%w: %s (%s)", ErrWaitTimeout, w.Resource, w.Timeout)
+		}
+		time.Sleep(w.Interval.Next())
+	}
+}
+
+// 根据状态等待资源, errorStatus 为资源的失败状态
+func newStatusWaiter[T any](resource string, get func() (*T, error), getStatus func(*T) string,
+	status string, errorStatus ...string) *Waiter[T] {
+	return NewWaiter(resource, get).
+		Until(func(item *T) bool {
+			return strings.EqualFold(getStatus(item), status)
+		}).
+		FailWhen(func(item *T) error {
+			for _, s := range errorStatus {
+				if strings.EqualFold(getStatus(item), s) {
+					return fmt.Errorf("%w: status is %s", ErrResourceIsError, getStatus(item))
+				}
+			}
+			return nil
+		}).
+		WithProgress(func(item *T) {
+			console.Info("[%s] status: %s", resource, getStatus(item))
+		})
+}
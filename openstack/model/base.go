@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 	"strings"
 )
@@ -46,6 +47,24 @@ func (resource Resource) IsError() bool {
 	return strings.EqualFold(resource.Status, "ERROR")
 }
 
+type Link struct {
+	Href string `json:"href"`
+	Rel  string `json:"rel"`
+}
+
+// 返回分页链接中下一页的 marker, 没有下一页时返回空字符串
+func NextMarker(links []Link) string {
+	for _, link := range links {
+		if link.Rel != "next" {
+			continue
+		}
+		if u, err := url.Parse(link.Href); err == nil {
+			return u.Query().Get("marker")
+		}
+	}
+	return ""
+}
+
 type ApiVersion struct {
 	Id         string `json:"id"`
 	MinVersion string `json:"min_version"`
@@ -136,3 +136,10 @@ type Backup struct {
 	Progress  string         `json:"os-extended-snapshot-attributes:progress,omitempty"`
 	Metadata  map[string]any `json:"metadata:progress,omitempty"`
 }
+
+type QuotaUsage struct {
+	Limit     int `json:"limit"`
+	InUse     int `json:"in_use"`
+	Reserved  int `json:"reserved"`
+	Allocated int `json:"allocated"`
+}
@@ -3,6 +3,7 @@ package neutron
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/BytemanD/easygo/pkg/stringutils"
@@ -15,24 +16,48 @@ type Route struct {
 	Destination   string `json:"destination,omitempty"`
 	HostRouteType string `json:"host_route_type,omitempty"`
 }
+
+func (route Route) String() string {
+	return fmt.Sprintf("%s via %s", route.Destination, route.Nexthop)
+}
+
+type ExternalGatewayInfo struct {
+	NetworkId        string    `json:"network_id"`
+	EnableSnat       *bool     `json:"enable_snat,omitempty"`
+	ExternalFixedIps []FixedIp `json:"external_fixed_ips,omitempty"`
+}
 type Router struct {
 	model.Resource
-	AdminStateUp          bool           `json:"admin_state_up,omitempty"`
-	Distributed           bool           `json:"distributed,omitempty"`
-	HA                    bool           `json:"ha,omitempty"`
-	Routes                []Route        `json:"routes,omitempty"`
-	RevsionNumber         int            `json:"revision_number,omitempty"`
-	ExternalGatewayInfo   map[string]any `json:"external_gateway_info,omitempty"`
-	AvailabilityZones     []string       `json:"availability_zones,omitempty"`
-	AvailabilityZoneHints []string       `json:"availability_zone_hints,omitempty"`
-	Tags                  []string       `json:"tags,omitempty"`
+	AdminStateUp          bool                 `json:"admin_state_up,omitempty"`
+	Distributed           bool                 `json:"distributed,omitempty"`
+	HA                    bool                 `json:"ha,omitempty"`
+	Routes                []Route              `json:"routes,omitempty"`
+	RevsionNumber         int                  `json:"revision_number,omitempty"`
+	ExternalGatewayInfo   *ExternalGatewayInfo `json:"external_gateway_info,omitempty"`
+	AvailabilityZones     []string             `json:"availability_zones,omitempty"`
+	AvailabilityZoneHints []string             `json:"availability_zone_hints,omitempty"`
+	Tags                  []string             `json:"tags,omitempty"`
 }
 
 func (router Router) MarshalExternalGatewayInfo() string {
+	if router.ExternalGatewayInfo == nil {
+		return ""
+	}
 	jsonString, _ := stringutils.JsonDumpsIndent(router.ExternalGatewayInfo)
 	return jsonString
 
 }
+func (router Router) RouteStrings() []string {
+	return lo.Map(router.Routes, func(item Route, _ int) string { return item.String() })
+}
+func (router Router) GatewayIps() []string {
+	if router.ExternalGatewayInfo == nil {
+		return []string{}
+	}
+	return lo.Map(router.ExternalGatewayInfo.ExternalFixedIps, func(item FixedIp, _ int) string {
+		return item.IpAddress
+	})
+}
 
 type Network struct {
 	model.Resource
@@ -59,6 +84,11 @@ type HostRouter struct {
 	NextHop     string `json:"nexthop,omitempty"`
 	Destination string `json:"destination,omitempty"`
 }
+
+func (route HostRouter) String() string {
+	return fmt.Sprintf("%s via %s", route.Destination, route.NextHop)
+}
+
 type Subnet struct {
 	model.Resource
 	NetworkId       string           `json:"network_id,omitempty"`
@@ -70,8 +100,54 @@ type Subnet struct {
 	EnableDhcp      bool             `json:"enable_dhcp,omitempty"`
 	GatewayIp       string           `json:"gateway_ip,omitempty"`
 	AllocationPools []AllocationPool `json:"allocation_pools,omitempty"`
+	DnsNameservers  []string         `json:"dns_nameservers,omitempty"`
+	Ipv6RaMode      string           `json:"ipv6_ra_mode,omitempty"`
+	Ipv6AddressMode string           `json:"ipv6_address_mode,omitempty"`
+	SubnetPoolId    string           `json:"subnetpool_id,omitempty"`
+}
+type SubnetPool struct {
+	model.Resource
+	Prefixes         []string `json:"prefixes,omitempty"`
+	DefaultPrefixLen int      `json:"default_prefixlen,omitempty"`
+	MinPrefixLen     int      `json:"min_prefixlen,omitempty"`
+	MaxPrefixLen     int      `json:"max_prefixlen,omitempty"`
+	IpVersion        int      `json:"ip_version,omitempty"`
+	Shared           bool     `json:"shared,omitempty"`
+	IsDefault        bool     `json:"is_default,omitempty"`
+}
+
+type SubnetIpAvailability struct {
+	SubnetId   string   `json:"subnet_id"`
+	SubnetName string   `json:"subnet_name"`
+	Cidr       string   `json:"cidr"`
+	IpVersion  int      `json:"ip_version"`
+	TotalIps   *big.Int `json:"total_ips"`
+	UsedIps    int64    `json:"used_ips"`
+}
+
+// 地址使用率(百分比), IPv6 子网的地址总数可能超出 int64 范围
+func (a SubnetIpAvailability) Usage() float64 {
+	if a.TotalIps == nil || a.TotalIps.Sign() <= 0 {
+		return 0
+	}
+	usage, _ := new(big.Float).Quo(
+		new(big.Float).SetInt64(a.UsedIps*100), new(big.Float).SetInt(a.TotalIps),
+	).Float64()
+	return usage
+}
+
+type NetworkIpAvailability struct {
+	NetworkId            string                 `json:"network_id"`
+	NetworkName          string                 `json:"network_name"`
+	ProjectId            string                 `json:"project_id,omitempty"`
+	TotalIps             *big.Int               `json:"total_ips"`
+	UsedIps              int64                  `json:"used_ips"`
+	SubnetIpAvailability []SubnetIpAvailability `json:"subnet_ip_availability"`
 }
 
+func (subnet Subnet) GetHostRoutesList() []string {
+	return lo.Map(subnet.HostRouters, func(route HostRouter, _ int) string { return route.String() })
+}
 func (subnet Subnet) GetAllocationPoolsList() []string {
 	return lo.Map(subnet.AllocationPools, func(pool AllocationPool, _ int) string {
 		return fmt.Sprintf("%s-%s", pool.Start, pool.End)
@@ -88,6 +164,18 @@ func (fixedIp FixedIp) String() string {
 	return string(data)
 }
 
+type AddressPair struct {
+	IpAddress  string `json:"ip_address"`
+	MacAddress string `json:"mac_address,omitempty"`
+}
+
+func (pair AddressPair) String() string {
+	if pair.MacAddress == "" {
+		return pair.IpAddress
+	}
+	return fmt.Sprintf("%s (%s)", pair.IpAddress, pair.MacAddress)
+}
+
 type Port struct {
 	model.Resource
 	AdminStateUp    bool           `json:"admin_state_up,omitempty"`
@@ -103,6 +191,9 @@ type Port struct {
 	DeviceId        string         `json:"device_id"`
 	SecurityGroups  []string       `json:"security_groups"`
 	RevsionNumber   int            `json:"revision_number"`
+
+	AllowedAddressPairs []AddressPair `json:"allowed_address_pairs,omitempty"`
+	PortSecurityEnabled *bool         `json:"port_security_enabled,omitempty"`
 }
 type Agent struct {
 	model.Resource
@@ -113,6 +204,11 @@ type Agent struct {
 	AvailabilityZone string `json:"availability_zone"`
 	Alive            bool   `json:"alive,omitempty"`
 	AdminStateUp     bool   `json:"admin_state_up,omitempty"`
+
+	HeartbeatTimestamp string         `json:"heartbeat_timestamp,omitempty"`
+	StartedAt          string         `json:"started_at,omitempty"`
+	Configurations     map[string]any `json:"configurations,omitempty"`
+	HaState            string         `json:"ha_state,omitempty"`
 }
 
 func (agent Agent) AliveEmoji() string {
@@ -121,6 +217,10 @@ func (agent Agent) AliveEmoji() string {
 	}
 	return "XXX"
 }
+func (agent Agent) MarshalConfigurations() string {
+	jsonString, _ := stringutils.JsonDumpsIndent(agent.Configurations)
+	return jsonString
+}
 
 type SecurityGroup struct {
 	model.Resource
@@ -134,8 +234,8 @@ type SecurityGroupRule struct {
 	SecurityGroupId string `json:"security_group_id,omitempty"`
 	Direction       string `json:"direction,omitempty"`
 	Ethertype       string `json:"ethertype,omitempty"`
-	PortRangeMin    int    `json:"port_range_min,omitempty"`
-	PortRangeMax    int    `json:"port_range_max,omitempty"`
+	PortRangeMin    *int   `json:"port_range_min,omitempty"`
+	PortRangeMax    *int   `json:"port_range_max,omitempty"`
 	Protocol        string `json:"protocol,omitempty"`
 	RemoteGroupId   string `json:"remote_group_id"`
 	RemoteIpPrefix  string `json:"remote_ip_prefix,omitempty"`
@@ -185,23 +285,56 @@ func (rule SecurityGroupRule) String() string {
 	if rule.RemoteIpPrefix != "" {
 		values = append(values, fmt.Sprintf("RemoteIpPrefix=%s", rule.RemoteIpPrefix))
 	}
-	if rule.PortRangeMin > 0 {
-		values = append(values, fmt.Sprintf("PortRangeMin=%s", rule.RemoteGroupId))
+	if rule.PortRangeMin != nil {
+		values = append(values, fmt.Sprintf("PortRangeMin=%d", *rule.PortRangeMin))
 	}
-	if rule.PortRangeMax > 0 {
-		values = append(values, fmt.Sprintf("PortRangeMax=%s", rule.RemoteGroupId))
+	if rule.PortRangeMax != nil {
+		values = append(values, fmt.Sprintf("PortRangeMax=%d", *rule.PortRangeMax))
 	}
 	return strings.Join(values, ",")
 }
+func (rule SecurityGroupRule) IsIcmp() bool {
+	return lo.Contains([]string{"icmp", "ipv6-icmp", "icmpv6", "1", "58"}, strings.ToLower(rule.Protocol))
+}
 func (rule SecurityGroupRule) PortRange() string {
-	portRange := ""
-	if rule.PortRangeMin > 0 {
-		portRange = fmt.Sprintf("%d", rule.PortRangeMin)
+	switch {
+	case rule.PortRangeMin == nil && rule.PortRangeMax == nil:
+		return ""
+	case rule.PortRangeMax == nil:
+		return fmt.Sprintf("%d:", *rule.PortRangeMin)
+	case rule.PortRangeMin == nil:
+		return fmt.Sprintf(":%d", *rule.PortRangeMax)
+	case *rule.PortRangeMin == *rule.PortRangeMax:
+		return fmt.Sprintf("%d", *rule.PortRangeMin)
+	default:
+		return fmt.Sprintf("%d:%d", *rule.PortRangeMin, *rule.PortRangeMax)
+	}
+}
+
+// 0.0.0.0/0 和 ::/0 与不指定远端地址等价
+func normalizeRemoteIpPrefix(prefix string) string {
+	if prefix == "0.0.0.0/0" || prefix == "::/0" {
+		return ""
 	}
-	if rule.PortRangeMax > 0 {
-		portRange += fmt.Sprintf("%s:%d", portRange, rule.PortRangeMax)
+	return prefix
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
 	}
-	return portRange
+	return *a == *b
+}
+
+// 判断两条规则是否重复, 忽略 ID、描述等属性
+func (rule SecurityGroupRule) Equal(other SecurityGroupRule) bool {
+	return strings.EqualFold(rule.Direction, other.Direction) &&
+		strings.EqualFold(rule.Ethertype, other.Ethertype) &&
+		strings.EqualFold(rule.Protocol, other.Protocol) &&
+		equalIntPtr(rule.PortRangeMin, other.PortRangeMin) &&
+		equalIntPtr(rule.PortRangeMax, other.PortRangeMax) &&
+		rule.RemoteGroupId == other.RemoteGroupId &&
+		normalizeRemoteIpPrefix(rule.RemoteIpPrefix) == normalizeRemoteIpPrefix(other.RemoteIpPrefix)
 }
 
 type QosRule struct {
@@ -212,14 +345,39 @@ type QosRule struct {
 	MaxKbps      int    `json:"max_kbps,omitempty"`
 	MinKbps      int    `json:"min_kbps,omitempty"`
 	MaxBurstKbps int    `json:"max_burst_kbps,omitempty"`
+	DscpMark     int    `json:"dscp_mark,omitempty"`
 }
 type QosPolicy struct {
 	model.Resource
 	Shared  bool      `json:"shared,omitempty"`
-	Default bool      `json:"default,omitempty"`
+	Default bool      `json:"is_default,omitempty"`
 	Rules   []QosRule `json:"rules"`
 }
 
+type SubPort struct {
+	PortId           string `json:"port_id"`
+	SegmentationType string `json:"segmentation_type,omitempty"`
+	SegmentationId   int    `json:"segmentation_id,omitempty"`
+}
+
+func (subPort SubPort) String() string {
+	return fmt.Sprintf("%s (%s %d)", subPort.PortId, subPort.SegmentationType, subPort.SegmentationId)
+}
+
+type Trunk struct {
+	model.Resource
+	PortId         string    `json:"port_id"`
+	AdminStateUp   bool      `json:"admin_state_up"`
+	SubPorts       []SubPort `json:"sub_ports"`
+	RevisionNumber int       `json:"revision_number"`
+}
+
+type QuotaUsage struct {
+	Limit    int `json:"limit"`
+	Used     int `json:"used"`
+	Reserved int `json:"reserved"`
+}
+
 type Routers []Router
 type Networks []Network
 type Ports []Port
@@ -35,11 +35,16 @@ type Flavor struct {
 	Swap         any        `json:"swap,omitempty"`
 	RXTXFactor   float32    `json:"rxtx_factor,omitempty"`
 	ExtraSpecs   ExtraSpecs `json:"extra_specs,omitempty"`
-	IsPublic     bool       `json:"os-flavor-access:is_public,omitempty"`
+	IsPublic     bool       `json:"os-flavor-access:is_public"`
 	Ephemeral    int        `json:"OS-FLV-DISABLED:ephemeral,omitempty"`
 	Disabled     bool       `json:"OS-FLV-DISABLED:disabled,omitempty"`
 }
 
+type FlavorAccess struct {
+	FlavorId string `json:"flavor_id"`
+	TenantId string `json:"tenant_id"`
+}
+
 func (flavor Flavor) Marshal() string {
 	flavorMarshal, _ := json.Marshal(flavor)
 	return string(flavorMarshal)
@@ -48,6 +53,17 @@ func (flavor Flavor) BaseInfo() string {
 	return fmt.Sprintf("vcpu=%d, ram=%d", flavor.Vcpus, flavor.Ram)
 }
 
+// 根磁盘、临时盘以及 swap 的总大小, 单位 GB
+func (flavor Flavor) TotalDiskGB() int {
+	swapMB := 0
+	switch swap := flavor.Swap.(type) {
+	case float64:
+		swapMB = int(swap)
+	case int:
+		swapMB = swap
+	}
+	return flavor.Disk + flavor.Ephemeral + (swapMB+1023)/1024
+}
 func (flavor Flavor) HumanRam() string {
 	return humanize.IBytes(uint64(flavor.Ram) * utility.MB)
 }
@@ -253,6 +269,8 @@ type Hypervisor struct {
 	VcpusUsed          int            `json:"vcpus_used"`
 	MemoryMB           int            `json:"memory_mb"`
 	MemoryMBUsed       int            `json:"memory_mb_used"`
+	LocalGb            int            `json:"local_gb"`
+	LocalGbUsed        int            `json:"local_gb_used"`
 	ExtraResources     map[string]any `json:"extra_resources"`
 	CpuInfo            CpuInfo        `json:"cpu_info"`
 
@@ -546,6 +564,7 @@ type Console struct {
 
 type Migration struct {
 	Id                int    `json:"id"`
+	UUID              string `json:"uuid,omitempty"`
 	OldInstanceTypeId int    `json:"old_instance_type_id"`
 	NewInstanceTypeId int    `json:"new_instance_type_id"`
 	InstanceUUID      string `json:"instance_uuid"`
@@ -667,3 +686,43 @@ type QuotaSet struct {
 	InjectedFileContentBytes int `json:"injected_file_content_bytes"`
 	InjectedFilePathBytes    int `json:"injected_file_path_bytes"`
 }
+
+type QuotaUsage struct {
+	Limit    int `json:"limit"`
+	InUse    int `json:"in_use"`
+	Reserved int `json:"reserved"`
+}
+
+type ServerUsage struct {
+	InstanceId string  `json:"instance_id"`
+	Name       string  `json:"name"`
+	TenantId   string  `json:"tenant_id"`
+	Flavor     string  `json:"flavor"`
+	Hours      float64 `json:"hours"`
+	Vcpus      int     `json:"vcpus"`
+	MemoryMB   int     `json:"memory_mb"`
+	LocalGB    int     `json:"local_gb"`
+	State      string  `json:"state"`
+	StartedAt  string  `json:"started_at"`
+	EndedAt    string  `json:"ended_at"`
+	Uptime     int     `json:"uptime"`
+}
+type TenantUsage struct {
+	TenantId           string        `json:"tenant_id"`
+	Start              string        `json:"start"`
+	Stop               string        `json:"stop"`
+	TotalHours         float64       `json:"total_hours"`
+	TotalVcpusUsage    float64       `json:"total_vcpus_usage"`
+	TotalMemoryMBUsage float64       `json:"total_memory_mb_usage"`
+	TotalLocalGBUsage  float64       `json:"total_local_gb_usage"`
+	ServerUsages       []ServerUsage `json:"server_usages,omitempty"`
+}
+
+// 分页查询时同一个项目的用量可能分布在多页中
+func (usage *TenantUsage) Merge(other TenantUsage) {
+	usage.TotalHours += other.TotalHours
+	usage.TotalVcpusUsage += other.TotalVcpusUsage
+	usage.TotalMemoryMBUsage += other.TotalMemoryMBUsage
+	usage.TotalLocalGBUsage += other.TotalLocalGBUsage
+	usage.ServerUsages = append(usage.ServerUsages, other.ServerUsages...)
+}
@@ -0,0 +1,63 @@
+package placement
+
+type ResourceProvider struct {
+	Uuid               string `json:"uuid"`
+	Name               string `json:"name"`
+	Generation         int    `json:"generation"`
+	ParentProviderUuid string `json:"parent_provider_uuid,omitempty"`
+	RootProviderUuid   string `json:"root_provider_uuid,omitempty"`
+}
+
+type Inventory struct {
+	Total           int     `json:"total"`
+	Reserved        int     `json:"reserved"`
+	MinUnit         int     `json:"min_unit"`
+	MaxUnit         int     `json:"max_unit"`
+	StepSize        int     `json:"step_size"`
+	AllocationRatio float64 `json:"allocation_ratio"`
+}
+
+// 可分配的资源总量
+func (inventory Inventory) Capacity() int {
+	return int(float64(inventory.Total-inventory.Reserved) * inventory.AllocationRatio)
+}
+
+type Allocation struct {
+	Generation int            `json:"generation,omitempty"`
+	Resources  map[string]int `json:"resources"`
+}
+
+// 资源提供者的库存及使用量
+type ProviderResource struct {
+	ResourceClass string
+	Inventory
+	Used int
+}
+
+func (r ProviderResource) Free() int {
+	return r.Capacity() - r.Used
+}
+
+type ConsumerAllocations struct {
+	Allocations map[string]Allocation `json:"allocations"`
+	ProjectId   string                `json:"project_id,omitempty"`
+	UserId      string                `json:"user_id,omitempty"`
+}
+
+type AllocationRequest struct {
+	Allocations map[string]Allocation `json:"allocations"`
+}
+
+type ProviderSummaryResource struct {
+	Capacity int `json:"capacity"`
+	Used     int `json:"used"`
+}
+type ProviderSummary struct {
+	Resources map[string]ProviderSummaryResource `json:"resources"`
+	Traits    []string                           `json:"traits,omitempty"`
+}
+
+type AllocationCandidates struct {
+	AllocationRequests []AllocationRequest        `json:"allocation_requests"`
+	ProviderSummaries  map[string]ProviderSummary `json:"provider_summaries"`
+}
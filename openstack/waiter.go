@@ -0,0 +1,19 @@
+package openstack
+
+import (
+	"github.com/BytemanD/skyman/openstack/internal"
+)
+
+// 通用的资源等待器, 用法参考各客户端的 XxxWaiter 方法
+type Waiter[T any] = internal.Waiter[T]
+
+const DEFAULT_WAIT_TIMEOUT = internal.DEFAULT_WAIT_TIMEOUT
+
+var (
+	ErrWaitTimeout     = internal.ErrWaitTimeout
+	ErrResourceIsError = internal.ErrResourceIsError
+)
+
+func NewWaiter[T any](resource string, get func() (*T, error)) *Waiter[T] {
+	return internal.NewWaiter(resource, get)
+}
@@ -17,6 +17,8 @@ type ServerCheckerInterface interface {
 	MakesureVolumeExist(attachment *nova.VolumeAttachment) error
 	MakesureVolumeNotExists(attachment *nova.VolumeAttachment) error
 	MakesureVolumeSizeIs(attachment *nova.VolumeAttachment, size uint) error
+	MakesureSubportExist(trunkId string, subPort neutron.SubPort) error
+	MakesureSubportNotExists(trunkId string, subPort neutron.SubPort) error
 }
 
 type ServerCheckers []ServerCheckerInterface
@@ -78,6 +80,22 @@ func (checkers ServerCheckers) MakesureVolumeSizeIs(attachment *nova.VolumeAttac
 	}
 	return nil
 }
+func (checkers ServerCheckers) MakesureSubportExist(trunkId string, subPort neutron.SubPort) error {
+	for _, checker := range checkers {
+		if err := checker.MakesureSubportExist(trunkId, subPort); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (checkers ServerCheckers) MakesureSubportNotExists(trunkId string, subPort neutron.SubPort) error {
+	for _, checker := range checkers {
+		if err := checker.MakesureSubportNotExists(trunkId, subPort); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
 func GetServerCheckers(client *openstack.Openstack, server *nova.Server, conf common.QGAChecker) (ServerCheckers, error) {
 	checkers := []ServerCheckerInterface{
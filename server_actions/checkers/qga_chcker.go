@@ -213,6 +213,120 @@ func (c QGAChecker) MakesureVolumeSizeIs(attachment *nova.VolumeAttachment, size
 	return fmt.Errorf("block device %s not exists on guest", attachment.Device)
 }
 
+// 根据 MAC 地址查找 guest 中的网卡名称
+func findGuestInterface(serverGuest guest.Guest, mac string) (string, error) {
+	result := serverGuest.Exec("ip -o link", true)
+	if result.Failed {
+		return "", fmt.Errorf("run qga command failed: %s", result.ErrData)
+	}
+	for _, line := range strings.Split(result.OutData, "\n") {
+		// 例如: 2: eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 ... link/ether fa:16:3e:00:00:01 brd ...
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.Contains(strings.ToLower(line), "link/ether "+strings.ToLower(mac)) {
+			continue
+		}
+		return strings.Split(strings.TrimSuffix(fields[1], ":"), "@")[0], nil
+	}
+	return "", fmt.Errorf("interface with mac %s not found on guest", mac)
+}
+func vlanInterfaceName(subPort neutron.SubPort) string {
+	return fmt.Sprintf("vlan%d", subPort.SegmentationId)
+}
+
+// guest 不会自动创建 VLAN 网卡, 先在父网卡上创建 VLAN 网卡并配置子端口的 IP 地址, 再检查 IP 地址是否生效
+func (c QGAChecker) MakesureSubportExist(trunkId string, subPort neutron.SubPort) error {
+	serverGuest := guest.Guest{Connection: c.Host, Domain: c.ServerId}
+	serverGuest.Connect()
+	if serverGuest.IsShutoff() {
+		console.Warn("[%s] guest is shutoff, skip to check vlan interfaces", c.ServerId)
+		return nil
+	}
+	trunk, err := c.Client.NeutronV2().GetTrunk(trunkId)
+	if err != nil {
+		return fmt.Errorf("get trunk failed: %s", err)
+	}
+	parentPort, err := c.Client.NeutronV2().GetPort(trunk.PortId)
+	if err != nil {
+		return fmt.Errorf("get parent port failed: %s", err)
+	}
+	port, err := c.Client.NeutronV2().GetPort(subPort.PortId)
+	if err != nil {
+		return fmt.Errorf("get subport failed: %s", err)
+	}
+	parent, err := findGuestInterface(serverGuest, parentPort.MACAddress)
+	if err != nil {
+		return err
+	}
+	name := vlanInterfaceName(subPort)
+	commands := []string{
+		fmt.Sprintf("ip link add link %s name %s address %s type vlan id %d",
+			parent, name, port.MACAddress, subPort.SegmentationId),
+		fmt.Sprintf("ip link set %s up", name),
+	}
+	for _, fixedIp := range port.FixedIps {
+		subnet, err := c.Client.NeutronV2().GetSubnet(fixedIp.SubnetId)
+		if err != nil {
+			return fmt.Errorf("get subnet failed: %s", err)
+		}
+		prefix := subnet.Cidr[strings.LastIndex(subnet.Cidr, "/"):]
+		commands = append(commands, fmt.Sprintf("ip addr add %s%s dev %s", fixedIp.IpAddress, prefix, name))
+	}
+	console.Info("[%s] creating vlan interface %s on %s", c.ServerId, name, parent)
+	for _, command := range commands {
+		if result := serverGuest.Exec(command, true); result.Failed || result.ErrData != "" {
+			return fmt.Errorf("run '%s' on guest failed: %s", command, result.ErrData)
+		}
+	}
+	result := serverGuest.Exec(fmt.Sprintf("ip -d link show %s", name), true)
+	if !strings.Contains(result.OutData, fmt.Sprintf("id %d ", subPort.SegmentationId)) {
+		return fmt.Errorf("vlan interface %s not found on guest", name)
+	}
+	fixedIps := lo.Map(port.FixedIps, func(item neutron.FixedIp, _ int) string { return item.IpAddress })
+	return utility.RetryWithErrors(
+		utility.RetryCondition{
+			Timeout:      time.Minute * 2,
+			IntervalMin:  time.Second,
+			IntervalMax:  time.Second * 10,
+			IntervalStep: time.Second,
+		},
+		[]string{"GuestHasNoIpaddressError"},
+		func() error {
+			ipaddrs := serverGuest.GetIpaddrs()
+			notFound := lo.Without(fixedIps, ipaddrs...)
+			if len(notFound) > 0 {
+				console.Warn("[%s] ip address %s not exists on guest", c.ServerId, strings.Join(notFound, ","))
+				return utility.NewGuestHasNoIpaddressError(notFound)
+			}
+			console.Info("[%s] vlan interface %s with ip address %s exists on guest",
+				c.ServerId, name, strings.Join(fixedIps, ","))
+			return nil
+		},
+	)
+}
+
+// 删除 guest 中的 VLAN 网卡, 并检查网卡已不存在
+func (c QGAChecker) MakesureSubportNotExists(trunkId string, subPort neutron.SubPort) error {
+	serverGuest := guest.Guest{Connection: c.Host, Domain: c.ServerId}
+	serverGuest.Connect()
+	if serverGuest.IsShutoff() {
+		console.Warn("[%s] guest is shutoff, skip to check vlan interfaces", c.ServerId)
+		return nil
+	}
+	name := vlanInterfaceName(subPort)
+	console.Info("[%s] deleting vlan interface %s", c.ServerId, name)
+	serverGuest.Exec(fmt.Sprintf("ip link del %s", name), true)
+
+	result := serverGuest.Exec("ip -o link", true)
+	if result.Failed {
+		return fmt.Errorf("run qga command failed: %s", result.ErrData)
+	}
+	if strings.Contains(result.OutData, fmt.Sprintf(" %s@", name)) {
+		return fmt.Errorf("vlan interface %s exists on guest", name)
+	}
+	console.Info("[%s] vlan interface %s not exists on guest", c.ServerId, name)
+	return nil
+}
+
 func GetQgaChecker(client *openstack.Openstack, server *nova.Server) (*QGAChecker, error) {
 	host, err := client.NovaV2().FindHypervisor(server.Host)
 	if err != nil {
@@ -2,6 +2,7 @@ package checkers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/BytemanD/go-console/console"
 	"github.com/BytemanD/skyman/openstack"
@@ -97,3 +98,38 @@ func (c ServerChecker) MakesureVolumeSizeIs(attachment *nova.VolumeAttachment, s
 	}
 	return nil
 }
+
+func (c ServerChecker) MakesureSubportExist(trunkId string, subPort neutron.SubPort) error {
+	trunk, err := c.Client.NeutronV2().GetTrunk(trunkId)
+	if err != nil {
+		return fmt.Errorf("get trunk failed: %s", err)
+	}
+	found := false
+	for _, item := range trunk.SubPorts {
+		if item.PortId == subPort.PortId && item.SegmentationId == subPort.SegmentationId {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("trunk %s has no subport: %s", trunkId, subPort)
+	}
+	if _, err := c.Client.NeutronV2().WaitPortStatus(subPort.PortId, "ACTIVE", time.Minute*2); err != nil {
+		return fmt.Errorf("subport %s is not active: %s", subPort.PortId, err)
+	}
+	console.Info("[%s] trunk %s has subport: %s", c.ServerId, trunkId, subPort)
+	return nil
+}
+func (c ServerChecker) MakesureSubportNotExists(trunkId string, subPort neutron.SubPort) error {
+	trunk, err := c.Client.NeutronV2().GetTrunk(trunkId)
+	if err != nil {
+		return fmt.Errorf("get trunk failed: %s", err)
+	}
+	for _, item := range trunk.SubPorts {
+		if item.PortId == subPort.PortId {
+			return fmt.Errorf("trunk %s has subport: %s", trunkId, subPort)
+		}
+	}
+	console.Info("[%s] trunk %s has no subport: %s", c.ServerId, trunkId, subPort.PortId)
+	return nil
+}
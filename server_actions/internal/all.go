@@ -18,35 +18,36 @@ import (
 )
 
 var (
-	ACTION_REBOOT            = "reboot"
-	ACTION_HARD_REBOOT       = "hard_reboot"
-	ACTION_STOP              = "stop"
-	ACTION_START             = "start"
-	ACTION_PAUSE             = "pause"
-	ACTION_UNPAUSE           = "unpause"
-	ACTION_MIGRATE           = "migrate"
-	ACTION_LIVE_MIGRATE      = "live_migrate"
-	ACTION_SHELVE            = "shelve"
-	ACTION_UNSHELVE          = "unshelve"
-	ACTION_TOGGLE_SHELVE     = "toggle_shelve"
-	ACTION_REBUILD           = "rebuild"
-	ACTION_RESIZE            = "resize"
-	ACTION_RENAME            = "rename"
-	ACTION_SET_NAME          = "setname"
-	ACTION_SUSPEND           = "suspend"
-	ACTION_RESUME            = "resume"
-	ACTION_TOGGLE_SUSPEND    = "toggle_suspend"
-	ACTION_ATTACH_NET        = "net_attach"
-	ACTION_ATTACH_PORT       = "port_attach"
-	ACTION_DETACH_PORT       = "port_detach"
-	ACTION_INTERFACE_HOTPLUG = "interface_hotplug"
-	ACTION_ATTACH_VOLUME     = "volume_attach"
-	ACTION_DETACH_VOLUME     = "volume_detach"
-	ACTION_VOLUME_HOTPLUG    = "volume_hotplug"
-	ACTION_VOLUME_EXTEND     = "volume_extend"
-	ACTION_REVERT_SYSTEM     = "revert_system"
-	ACTION_SYSTEM_SNAPSHOT   = "system_snapshot"
-	ACTION_NOP               = "nop"
+	ACTION_REBOOT                = "reboot"
+	ACTION_HARD_REBOOT           = "hard_reboot"
+	ACTION_STOP                  = "stop"
+	ACTION_START                 = "start"
+	ACTION_PAUSE                 = "pause"
+	ACTION_UNPAUSE               = "unpause"
+	ACTION_MIGRATE               = "migrate"
+	ACTION_LIVE_MIGRATE          = "live_migrate"
+	ACTION_SHELVE                = "shelve"
+	ACTION_UNSHELVE              = "unshelve"
+	ACTION_TOGGLE_SHELVE         = "toggle_shelve"
+	ACTION_REBUILD               = "rebuild"
+	ACTION_RESIZE                = "resize"
+	ACTION_RENAME                = "rename"
+	ACTION_SET_NAME              = "setname"
+	ACTION_SUSPEND               = "suspend"
+	ACTION_RESUME                = "resume"
+	ACTION_TOGGLE_SUSPEND        = "toggle_suspend"
+	ACTION_ATTACH_NET            = "net_attach"
+	ACTION_ATTACH_PORT           = "port_attach"
+	ACTION_DETACH_PORT           = "port_detach"
+	ACTION_INTERFACE_HOTPLUG     = "interface_hotplug"
+	ACTION_TRUNK_SUBPORT_HOTPLUG = "trunk_subport_hotplug"
+	ACTION_ATTACH_VOLUME         = "volume_attach"
+	ACTION_DETACH_VOLUME         = "volume_detach"
+	ACTION_VOLUME_HOTPLUG        = "volume_hotplug"
+	ACTION_VOLUME_EXTEND         = "volume_extend"
+	ACTION_REVERT_SYSTEM         = "revert_system"
+	ACTION_SYSTEM_SNAPSHOT       = "system_snapshot"
+	ACTION_NOP                   = "nop"
 )
 
 type ServerAction interface {
@@ -419,6 +420,9 @@ func init() {
 	VALID_ACTIONS.register(ACTION_INTERFACE_HOTPLUG, func(s *nova.Server, c *openstack.Openstack) ServerAction {
 		return &ServerAttachHotPlug{ServerActionTest: ServerActionTest{Server: s, Client: c}}
 	})
+	VALID_ACTIONS.register(ACTION_TRUNK_SUBPORT_HOTPLUG, func(s *nova.Server, c *openstack.Openstack) ServerAction {
+		return &ServerTrunkSubportHotPlug{ServerActionTest: ServerActionTest{Server: s, Client: c}}
+	})
 	VALID_ACTIONS.register(ACTION_VOLUME_HOTPLUG, func(s *nova.Server, c *openstack.Openstack) ServerAction {
 		return &ServerVolumeHotPlug{ServerActionTest: ServerActionTest{Server: s, Client: c}}
 	})
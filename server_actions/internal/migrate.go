@@ -2,7 +2,6 @@ package internal
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
 	"syscall"
 	"time"
@@ -39,29 +38,7 @@ func (t *ServerLiveMigrate) createClientServer() error {
 	return nil
 }
 func (t *ServerLiveMigrate) waitServerBooted(serverId string) error {
-	return utility.RetryWithErrors(
-		utility.RetryCondition{
-			Timeout:      time.Minute * 10,
-			IntervalMin:  time.Second * 2,
-			IntervalStep: time.Second,
-			IntervalMax:  time.Second * 10,
-		},
-		[]string{"ServerNotBooted"},
-		func() error {
-			consoleLog, err := t.Client.NovaV2().GetServerConsoleLog(serverId, 50)
-			if err != nil {
-				return fmt.Errorf("get console log failed: %s", err)
-			}
-			// TODO: set key world by config file.
-			reg := regexp.MustCompile(` login:`)
-			result := reg.FindStringSubmatch(consoleLog.Output)
-			console.Debug("[%s] client console log: %s", t.ServerId(), consoleLog.Output)
-			if len(result) > 0 {
-				return nil
-			}
-			return utility.NewServerNotBootedError(serverId)
-		},
-	)
+	return t.Client.NovaV2().WaitServerGuestBooted(serverId, time.Minute*10)
 }
 
 func (t *ServerLiveMigrate) getClientGuest() (*guest.Guest, error) {
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/BytemanD/go-console/console"
+	"github.com/BytemanD/skyman/openstack/model/neutron"
+	"github.com/samber/lo"
+)
+
+const MIN_SEGMENTATION_ID = 100
+
+type ServerTrunkSubportHotPlug struct {
+	ServerActionTest
+	subPorts []neutron.SubPort
+}
+
+func (t *ServerTrunkSubportHotPlug) Skip() (bool, string) {
+	if !t.Server.IsActive() {
+		return true, "server is not active"
+	}
+	return false, ""
+}
+
+// 根据虚拟机的网卡查找 trunk, 网卡为 trunk 的父端口
+func (t *ServerTrunkSubportHotPlug) findTrunk() (*neutron.Trunk, error) {
+	interfaces, err := t.Client.NovaV2().ListServerInterfaces(t.Server.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, vif := range interfaces {
+		trunks, err := t.Client.NeutronV2().ListTrunk(url.Values{"port_id": []string{vif.PortId}})
+		if err != nil {
+			return nil, err
+		}
+		if len(trunks) > 0 {
+			return &trunks[0], nil
+		}
+	}
+	return nil, fmt.Errorf("server has no trunk")
+}
+
+func nextSegmentationId(trunk *neutron.Trunk, subPorts []neutron.SubPort) int {
+	used := lo.Map(slices.Concat(trunk.SubPorts, subPorts), func(item neutron.SubPort, _ int) int {
+		return item.SegmentationId
+	})
+	id := MIN_SEGMENTATION_ID
+	for lo.Contains(used, id) {
+		id++
+	}
+	return id
+}
+
+func (t *ServerTrunkSubportHotPlug) Start() error {
+	trunk, err := t.findTrunk()
+	if err != nil {
+		return err
+	}
+	console.Info("[%s] found trunk %s", t.ServerId(), trunk.Id)
+	serverCheckers, err := t.getCheckers()
+	if err != nil {
+		return fmt.Errorf("get server checker failed: %s", err)
+	}
+	for i := 1; i <= t.Config.TrunkSubportHotplug.Nums; i++ {
+		nextNetwork, err := t.nextNetwork()
+		if err != nil {
+			return err
+		}
+		console.Info("[%s] creating port for subport %d", t.ServerId(), i)
+		port, err := t.Client.NeutronV2().CreatePort(map[string]any{
+			"network_id": nextNetwork,
+		})
+		if err != nil {
+			console.Error("[%s] create port failed: %s", t.ServerId(), err)
+			return err
+		}
+		subPort := neutron.SubPort{
+			PortId: port.Id, SegmentationType: "vlan",
+			SegmentationId: nextSegmentationId(trunk, t.subPorts),
+		}
+		t.subPorts = append(t.subPorts, subPort)
+
+		console.Info("[%s] adding subport %s", t.ServerId(), subPort)
+		if _, err := t.Client.NeutronV2().TrunkAddSubports(trunk.Id, []neutron.SubPort{subPort}); err != nil {
+			return err
+		}
+		if err := serverCheckers.MakesureSubportExist(trunk.Id, subPort); err != nil {
+			return err
+		}
+	}
+
+	for _, subPort := range t.subPorts {
+		console.Info("[%s] removing subport %s", t.ServerId(), subPort)
+		if _, err := t.Client.NeutronV2().TrunkRemoveSubports(trunk.Id, []string{subPort.PortId}); err != nil {
+			return err
+		}
+		if err := serverCheckers.MakesureSubportNotExists(trunk.Id, subPort); err != nil {
+			return err
+		}
+	}
+	return t.ServerMustNotError()
+}
+
+func (t ServerTrunkSubportHotPlug) TearDown() error {
+	deleteFailed := []string{}
+	console.Info("[%s] cleanup %d subports", t.ServerId(), len(t.subPorts))
+	if trunk, err := t.findTrunk(); err == nil {
+		// 操作失败时子端口可能仍在 trunk 中, 需要先移除才能删除端口
+		subPortIds := lo.Intersect(
+			lo.Map(trunk.SubPorts, func(item neutron.SubPort, _ int) string { return item.PortId }),
+			lo.Map(t.subPorts, func(item neutron.SubPort, _ int) string { return item.PortId }),
+		)
+		if len(subPortIds) > 0 {
+			if _, err := t.Client.NeutronV2().TrunkRemoveSubports(trunk.Id, subPortIds); err != nil {
+				console.Error("[%s] remove subports failed: %s", t.ServerId(), err)
+			}
+		}
+	}
+	for _, subPort := range t.subPorts {
+		console.Info("[%s] deleting port %s", t.ServerId(), subPort.PortId)
+		if err := t.Client.NeutronV2().DeletePort(subPort.PortId); err != nil {
+			deleteFailed = append(deleteFailed, subPort.PortId)
+			console.Error("[%s] delete port %s failed: %s", t.ServerId(), subPort.PortId, err)
+		}
+	}
+	if len(deleteFailed) > 0 {
+		return fmt.Errorf("delete port(s) %s failed", strings.Join(deleteFailed, ","))
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package utility
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// 使用本地私钥解密 nova 元数据服务中保存的密码
+func DecryptServerPassword(encrypted string, privateKeyFile string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("decode password failed: %w", err)
+	}
+	keyBytes, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return "", err
+	}
+	key, err := ssh.ParseRawPrivateKey(keyBytes)
+	if err != nil {
+		return "", fmt.Errorf("parse private key failed: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("private key %s is not a RSA key", privateKeyFile)
+	}
+	password, err := rsa.DecryptPKCS1v15(rand.Reader, rsaKey, data)
+	if err != nil {
+		return "", fmt.Errorf("decrypt password failed: %w", err)
+	}
+	return string(password), nil
+}
+
+const (
+	KEY_ALGORITHM_ED25519 = "ed25519"
+	KEY_ALGORITHM_RSA     = "rsa"
+)
+
+// 在本地生成密钥对, 返回 PEM 格式的私钥以及私钥对应的公钥
+func GenerateKeyPair(algorithm string, bits int) ([]byte, crypto.PublicKey, error) {
+	var privateKey crypto.PrivateKey
+	var publicKey crypto.PublicKey
+	switch algorithm {
+	case KEY_ALGORITHM_ED25519:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		privateKey, publicKey = priv, pub
+	case KEY_ALGORITHM_RSA:
+		if bits < 2048 {
+			return nil, nil, fmt.Errorf("RSA key size must be at least 2048, got %d", bits)
+		}
+		priv, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, nil, err
+		}
+		privateKey, publicKey = priv, &priv.PublicKey
+	default:
+		return nil, nil, fmt.Errorf("unsupported key algorithm %s", algorithm)
+	}
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(block), publicKey, nil
+}
+
+// 生成 authorized_keys 格式的公钥
+func MarshalSSHPublicKey(publicKey crypto.PublicKey, comment string) (string, error) {
+	sshKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshKey)))
+	if comment != "" {
+		line = line + " " + comment
+	}
+	return line, nil
+}
+
+// 使用私钥生成自签名证书, 用于 x509 类型的密钥对
+func GenerateX509Certificate(privateKeyPEM []byte, commonName string, days int) (string, error) {
+	privateKey, err := ssh.ParseRawPrivateKey(privateKeyPEM)
+	if err != nil {
+		return "", err
+	}
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return "", fmt.Errorf("private key can not be used to sign certificate")
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", err
+	}
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().AddDate(0, 0, days),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, signer.Public(), signer)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), nil
+}
+
+// 读取私钥文件, 返回私钥对应的公钥
+func LoadPrivateKeyPublic(privateKeyFile string) (crypto.PublicKey, error) {
+	keyBytes, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ssh.ParseRawPrivateKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key failed: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key %s", privateKeyFile)
+	}
+	return signer.Public(), nil
+}
+
+// 按照 nova 的算法计算公钥指纹: ssh 类型为 MD5, x509 类型为证书的 SHA1
+func KeypairFingerprint(keyType string, publicKey string) (string, error) {
+	if keyType == "x509" {
+		block, _ := pem.Decode([]byte(publicKey))
+		if block == nil {
+			return "", fmt.Errorf("invalid x509 certificate")
+		}
+		sum := sha1.Sum(block.Bytes)
+		return colonHex(sum[:]), nil
+	}
+	sshKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return "", err
+	}
+	return ssh.FingerprintLegacyMD5(sshKey), nil
+}
+
+// 获取 ssh 公钥或 x509 证书中的公钥
+func ParseKeypairPublicKey(keyType string, publicKey string) (crypto.PublicKey, error) {
+	if keyType == "x509" {
+		block, _ := pem.Decode([]byte(publicKey))
+		if block == nil {
+			return nil, fmt.Errorf("invalid x509 certificate")
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	sshKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return nil, err
+	}
+	cryptoKey, ok := sshKey.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %s", sshKey.Type())
+	}
+	return cryptoKey.CryptoPublicKey(), nil
+}
+
+type equalPublicKey interface {
+	Equal(crypto.PublicKey) bool
+}
+
+// 判断两个公钥是否相同
+func PublicKeyEqual(a, b crypto.PublicKey) bool {
+	key, ok := a.(equalPublicKey)
+	return ok && key.Equal(b)
+}
+
+func colonHex(data []byte) string {
+	items := make([]string, len(data))
+	for i, b := range data {
+		items[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(items, ":")
+}
+
+type AuthorizedKey struct {
+	PublicKey string
+	Comment   string
+}
+
+// 解析 authorized_keys 格式的内容, 支持多行
+func ParseAuthorizedKeys(data []byte) ([]AuthorizedKey, error) {
+	keys := []AuthorizedKey{}
+	for len(data) > 0 {
+		sshKey, comment, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			// 无法解析的行会被跳过, 仅当剩余内容中没有公钥时才返回错误
+			if len(keys) > 0 {
+				break
+			}
+			return nil, err
+		}
+		keys = append(keys, AuthorizedKey{
+			PublicKey: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshKey))),
+			Comment:   comment,
+		})
+		data = rest
+	}
+	return keys, nil
+}
@@ -16,7 +16,6 @@ type GuestHasNoIpaddressError ErrArgs
 type PingLossPackage ErrArgs
 type ServerNotStopped ErrArgs
 type SnapshotIsNotAvailable ErrArgs
-type ServerNotBooted ErrArgs
 type ImageNotActive ErrArgs
 
 func (e ActionNotFinishedError) Error() string {
@@ -44,9 +43,6 @@ func (e ImageNotActive) Error() string {
 	return fmt.Sprintf("image %s is not active", e.Args...)
 }
 
-func (e ServerNotBooted) Error() string {
-	return fmt.Sprintf("server %s is not booted", e.Args...)
-}
 func NewActionNotFinishedError(args ...any) ActionNotFinishedError {
 	return ActionNotFinishedError{Args: args}
 }
@@ -68,9 +64,6 @@ func NewServerNotStopped(serverId string) ServerNotStopped {
 func NewSnapshotIsNotAvailable(snapshotId string) SnapshotIsNotAvailable {
 	return SnapshotIsNotAvailable{Args: []any{snapshotId}}
 }
-func NewServerNotBootedError(serverId string) ServerNotBooted {
-	return ServerNotBooted{Args: []any{serverId}}
-}
 func NewImageNotActiveError(imageId string) ImageNotActive {
 	return ImageNotActive{Args: []any{imageId}}
 }
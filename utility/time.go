@@ -0,0 +1,15 @@
+package utility
+
+import "time"
+
+// 解析 openstack 返回的时间, 不带时区, 为 UTC 时间
+func ParseUTCTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range []string{"2006-01-02T15:04:05.000000", "2006-01-02T15:04:05"} {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}